@@ -0,0 +1,35 @@
+package dsn
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Canonical builds d and returns a normalized form of the DSN for deduplication and change detection: query
+// parameters are sorted and, for URL DSNs, the scheme and host are lowercased. Defaults such as the port are
+// expanded by the drivers' Build. Two DSNs that connect the same way have the same canonical form, so it can be
+// hashed; like Build's output, it contains the password.
+func Canonical(d DSN) (string, error) {
+	ds, err := d.Build()
+	if err != nil {
+		return "", err
+	}
+
+	base, query, _ := strings.Cut(ds, "?")
+
+	if u, err := url.Parse(base); err == nil && u.Scheme != "" && u.Host != "" {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		base = u.String()
+	}
+
+	if query == "" {
+		return base, nil
+	}
+
+	params := strings.Split(query, "&")
+	slices.Sort(params)
+
+	return base + "?" + strings.Join(params, "&"), nil
+}
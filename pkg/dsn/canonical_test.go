@@ -0,0 +1,97 @@
+package dsn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+)
+
+type rawDSN string
+
+func (r rawDSN) Build() (string, error) {
+	return string(r), nil
+}
+
+func TestCanonical_EquivalentPostgresConfigs(t *testing.T) {
+	a := &postgres.Config{
+		Host:       "DB.Example.com",
+		User:       "app",
+		Password:   "secret",
+		Database:   "orders",
+		SSLMode:    "require",
+		SearchPath: "public",
+	}
+	b := &postgres.Config{
+		Host:       "db.example.com",
+		Port:       5432,
+		User:       "app",
+		Password:   "secret",
+		Database:   "orders",
+		SearchPath: "public",
+		SSLMode:    "require",
+	}
+
+	ca, err := dsn.Canonical(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cb, err := dsn.Canonical(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ca != cb {
+		t.Errorf("canonical forms differ:\n%s\n%s", ca, cb)
+	}
+
+	b.Database = "billing"
+	if cb, _ := dsn.Canonical(b); ca == cb {
+		t.Errorf("expected different databases to canonicalize differently, got %s", ca)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b rawDSN
+	}{
+		{
+			name: "url param order and case",
+			a:    "POSTGRES://u:p@DB.internal:5432/app?sslmode=require&application_name=api",
+			b:    "postgres://u:p@db.internal:5432/app?application_name=api&sslmode=require",
+		},
+		{
+			name: "mysql param order",
+			a:    "u:p@tcp(db:3306)/app?parseTime=true&charset=utf8mb4",
+			b:    "u:p@tcp(db:3306)/app?charset=utf8mb4&parseTime=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ca, err := dsn.Canonical(tt.a)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cb, err := dsn.Canonical(tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ca != cb {
+				t.Errorf("canonical forms differ:\n%s\n%s", ca, cb)
+			}
+		})
+	}
+}
+
+func TestCanonical_BuildError(t *testing.T) {
+	_, err := dsn.Canonical(&postgres.Config{})
+	if !errors.Is(err, postgres.ErrPostgresHostRequired) {
+		t.Errorf("error: got %v, want %v", err, postgres.ErrPostgresHostRequired)
+	}
+}
@@ -0,0 +1,83 @@
+package dsn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownDriver is returned when a driver has no database/sql driver name.
+var ErrUnknownDriver = errors.New("dsn: unknown driver")
+
+// sqlDriverNames maps driver names to the database/sql driver registered by the usual import:
+//
+//	postgres  -> "pgx"       github.com/jackc/pgx/v5/stdlib
+//	mysql     -> "mysql"     github.com/go-sql-driver/mysql
+//	oracle    -> "oracle"    github.com/sijms/go-ora/v2
+//	sqlserver -> "sqlserver" github.com/microsoft/go-mssqldb
+//	sqlite    -> "sqlite3"   github.com/mattn/go-sqlite3
+//
+// mongo and redis have no database/sql driver.
+var sqlDriverNames = map[string]string{
+	"mysql":     "mysql",
+	"oracle":    "oracle",
+	"postgres":  "pgx",
+	"sqlite":    "sqlite3",
+	"sqlserver": "sqlserver",
+}
+
+var (
+	sqlDriverOverridesMu sync.RWMutex
+	sqlDriverOverrides   = map[string]string{}
+)
+
+// SetSQLDriverName overrides the database/sql driver name used for driver, e.g. when pgx is
+// registered under a name other than "pgx". An empty name removes the override.
+func SetSQLDriverName(driver, name string) {
+	sqlDriverOverridesMu.Lock()
+	defer sqlDriverOverridesMu.Unlock()
+
+	if name == "" {
+		delete(sqlDriverOverrides, driver)
+		return
+	}
+	sqlDriverOverrides[driver] = name
+}
+
+// SQLDriverName returns the database/sql driver name to pass to sql.Open for driver,
+// or an empty string when the driver has no database/sql driver.
+func SQLDriverName(driver string) string {
+	sqlDriverOverridesMu.RLock()
+	name, ok := sqlDriverOverrides[driver]
+	sqlDriverOverridesMu.RUnlock()
+
+	if ok {
+		return name
+	}
+	return sqlDriverNames[driver]
+}
+
+// TestConnection builds d, opens it with the database/sql driver for driver and pings it.
+// The package itself imports no SQL driver: the caller registers it with a blank import
+// of the package listed for driver in the table above, or overrides the name with SetSQLDriverName.
+func TestConnection(ctx context.Context, driver string, d DSN) error {
+	name := SQLDriverName(driver)
+	if name == "" {
+		return fmt.Errorf("%w: %s", ErrUnknownDriver, driver)
+	}
+
+	ds, err := d.Build()
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(name, ds)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,21 @@
+package dsn
+
+// Describe builds d and returns the DSN with its password masked by MaskConnectionString, e.g. for a
+// CLI dry run that shows what would be used without connecting. driver selects the masking format.
+func Describe(driver string, d DSN) (string, error) {
+	ds, err := d.Build()
+	if err != nil {
+		return "", err
+	}
+
+	return MaskConnectionString(driver, ds), nil
+}
+
+// DescribeFromBytes loads the YAML document data into cfg with LoadStrict and describes it like Describe.
+func DescribeFromBytes(driver string, data []byte, cfg DSN) (string, error) {
+	if err := LoadStrict(data, cfg); err != nil {
+		return "", err
+	}
+
+	return Describe(driver, cfg)
+}
@@ -16,3 +16,53 @@ type DSN interface {
 	//   - error: An error if validation fails or the DSN cannot be built
 	Build() (string, error)
 }
+
+// Endpoint is implemented by DSN configurations that connect to a single host and port.
+// It allows the target address to be inspected and redirected, for example through an SSH tunnel.
+type Endpoint interface {
+	DSN
+
+	// HostPort returns the host and port the DSN connects to. A port left unset is reported
+	// as the database default port, or as 0 when the driver discovers it at connect time, like
+	// for a SQL Server named instance.
+	HostPort() (string, int)
+
+	// SetHostPort replaces the host and port the DSN connects to.
+	SetHostPort(host string, port int)
+}
+
+// ConnectionInfo is implemented by DSN configurations that can report where they connect without building
+// and reparsing the DSN, e.g. for logging or routing. The host and port are reported like Endpoint.HostPort.
+type ConnectionInfo interface {
+	// HostPort returns the host and port the DSN connects to, reporting an unset port like Endpoint.HostPort.
+	HostPort() (string, int)
+
+	// DatabaseName returns the database, or the Oracle service name, the DSN connects to.
+	DatabaseName() string
+}
+
+// ParamProvider is implemented by DSN configurations that accept arbitrary driver parameters beyond their typed
+// fields. The parameters are appended to the built DSN with AppendParams.
+type ParamProvider interface {
+	// ExtraParams returns the parameters to pass through to the driver, keyed by name.
+	ExtraParams() map[string]string
+}
+
+// Annotated is implemented by DSN configurations that accept a meta block of annotations for humans, such as owner
+// or description. The block is decoded, also by LoadStrict, but never emitted into the DSN.
+type Annotated interface {
+	// Meta returns the annotations from the meta block.
+	Meta() map[string]string
+}
+
+// Annotations is embedded by the driver configs with a yaml ",inline" tag to accept the meta block described by
+// Annotated.
+type Annotations struct {
+	// Metadata holds the annotations, keyed by name (optional).
+	Metadata map[string]string `yaml:"meta"`
+}
+
+// Meta returns Metadata.
+func (a Annotations) Meta() map[string]string {
+	return a.Metadata
+}
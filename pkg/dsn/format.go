@@ -0,0 +1,12 @@
+package dsn
+
+import "fmt"
+
+// FormatPtr renders the value p points to, or <nil> when p is nil, for optional fields in String methods.
+func FormatPtr[T any](p *T) string {
+	if p == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprint(*p)
+}
@@ -0,0 +1,46 @@
+package dsn
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// FormatHostPort joins host and port into a host:port address, bracketing IPv6 literals
+// as in [::1]:5432. A host that is already bracketed is not bracketed twice.
+func FormatHostPort(host string, port int) string {
+	return net.JoinHostPort(unbracket(host), strconv.Itoa(port))
+}
+
+// FormatHost returns host bracketed when it is an IPv6 literal, for addresses without a port.
+func FormatHost(host string) string {
+	h := unbracket(host)
+	if strings.Contains(h, ":") {
+		return "[" + h + "]"
+	}
+
+	return h
+}
+
+// unbracket removes the brackets around an IPv6 literal such as [::1].
+func unbracket(host string) string {
+	if len(host) > 1 && host[0] == '[' && host[len(host)-1] == ']' {
+		return host[1 : len(host)-1]
+	}
+
+	return host
+}
+
+// ValidHost reports whether host is usable as a hostname or IP address in a connection string.
+// It rejects whitespace, control characters and the delimiters that could break out of a DSN
+// or Oracle descriptor, such as '(', ')', '@' and '/'. Brackets are only accepted around an IPv6 literal.
+func ValidHost(host string) bool {
+	h := unbracket(host)
+	for _, r := range h {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune("()@/\\?#,;=[]'\"", r) {
+			return false
+		}
+	}
+
+	return h != ""
+}
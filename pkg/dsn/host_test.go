@@ -0,0 +1,83 @@
+package dsn
+
+import "testing"
+
+func TestFormatHostPort(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		port int
+		want string
+	}{
+		{name: "hostname", host: "db.example.com", port: 5432, want: "db.example.com:5432"},
+		{name: "ipv4", host: "10.0.0.1", port: 3306, want: "10.0.0.1:3306"},
+		{name: "ipv6 loopback", host: "::1", port: 3306, want: "[::1]:3306"},
+		{name: "ipv6", host: "2001:db8::1", port: 5432, want: "[2001:db8::1]:5432"},
+		{name: "ipv6 bracketed", host: "[2001:db8::1]", port: 1521, want: "[2001:db8::1]:1521"},
+		{name: "ipv6 zone", host: "fe80::1%eth0", port: 1433, want: "[fe80::1%eth0]:1433"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatHostPort(tt.host, tt.port); got != tt.want {
+				t.Errorf("FormatHostPort(%q, %d) = %q, want %q", tt.host, tt.port, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "db.example.com", want: "db.example.com"},
+		{host: "10.0.0.1", want: "10.0.0.1"},
+		{host: "::1", want: "[::1]"},
+		{host: "[::1]", want: "[::1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := FormatHost(tt.host); got != tt.want {
+				t.Errorf("FormatHost(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "localhost", want: true},
+		{host: "db-1.example.com", want: true},
+		{host: "10.0.0.1", want: true},
+		{host: "::1", want: true},
+		{host: "[2001:db8::1]", want: true},
+		{host: "fe80::1%eth0", want: true},
+		{host: "", want: false},
+		{host: "[]", want: false},
+		{host: "evil)(INJECT=1", want: false},
+		{host: "user:pass@evil", want: false},
+		{host: "db host", want: false},
+		{host: "db\thost", want: false},
+		{host: "db\nhost", want: false},
+		{host: "db\x00host", want: false},
+		{host: "db/extra", want: false},
+		{host: "db?param=1", want: false},
+		{host: "db#frag", want: false},
+		{host: "db,other", want: false},
+		{host: "[::1", want: false},
+		{host: "db]", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := ValidHost(tt.host); got != tt.want {
+				t.Errorf("ValidHost(%q) = %t, want %t", tt.host, got, tt.want)
+			}
+		})
+	}
+}
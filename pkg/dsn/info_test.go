@@ -0,0 +1,70 @@
+package dsn_test
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+)
+
+func TestConnectionInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		info         dsn.ConnectionInfo
+		wantHost     string
+		wantPort     int
+		wantDatabase string
+	}{
+		{
+			name:         "mysql default port",
+			info:         &mysql.Config{Host: "mysql.internal", Database: "orders"},
+			wantHost:     "mysql.internal",
+			wantPort:     3306,
+			wantDatabase: "orders",
+		},
+		{
+			name:         "postgres explicit port",
+			info:         &postgres.Config{Host: "pg.internal", Port: 6432, Database: "billing"},
+			wantHost:     "pg.internal",
+			wantPort:     6432,
+			wantDatabase: "billing",
+		},
+		{
+			name:         "postgres default port",
+			info:         &postgres.Config{Host: "pg.internal", Database: "billing"},
+			wantHost:     "pg.internal",
+			wantPort:     5432,
+			wantDatabase: "billing",
+		},
+		{
+			name:         "oracle service name",
+			info:         &oracle.StandaloneConfig{Host: "ora.internal", ServiceName: "ORCLPDB1"},
+			wantHost:     "ora.internal",
+			wantPort:     1521,
+			wantDatabase: "ORCLPDB1",
+		},
+		{
+			name:         "sqlserver default port",
+			info:         &sqlserver.Config{Host: "mssql.internal", Database: "crm"},
+			wantHost:     "mssql.internal",
+			wantPort:     1433,
+			wantDatabase: "crm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := tt.info.HostPort()
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("HostPort() = %s, %d, want %s, %d", host, port, tt.wantHost, tt.wantPort)
+			}
+
+			if got := tt.info.DatabaseName(); got != tt.wantDatabase {
+				t.Errorf("DatabaseName() = %q, want %q", got, tt.wantDatabase)
+			}
+		})
+	}
+}
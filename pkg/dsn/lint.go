@@ -0,0 +1,77 @@
+package dsn
+
+import "fmt"
+
+// MinPasswordLength is the password length below which LintPassword reports a warning.
+const MinPasswordLength = 12
+
+// Severity ranks how strongly a Warning should be acted upon.
+type Severity string
+
+const (
+	// SeverityInfo marks advice that is usually worth following but often deliberately ignored.
+	SeverityInfo Severity = "info"
+
+	// SeverityWarning marks settings that weaken security or reliability.
+	SeverityWarning Severity = "warning"
+)
+
+// Warning is a non-fatal advisory about a configuration that passes validation.
+type Warning struct {
+	// Field is the configuration key the warning refers to, as spelled in YAML.
+	Field string
+
+	// Severity ranks the warning.
+	Severity Severity
+
+	// Message describes the problem.
+	Message string
+}
+
+// String formats the warning as "severity: field: message".
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s: %s", w.Severity, w.Field, w.Message)
+}
+
+// Warnings is a list of advisories, as returned by Lint.
+type Warnings []Warning
+
+// Fields returns the severity of each warning keyed by its field. A field with several warnings keeps the last one.
+func (w Warnings) Fields() map[string]Severity {
+	fields := make(map[string]Severity, len(w))
+	for _, warning := range w {
+		fields[warning.Field] = warning.Severity
+	}
+
+	return fields
+}
+
+// Linter is implemented by configurations that can report best-practice advisories.
+// Drivers contribute their own checks by implementing it.
+type Linter interface {
+	// Lint returns the advisories for the configuration, or nil when there are none.
+	Lint() []Warning
+}
+
+// Lint returns the advisories reported by d when it implements Linter, or nil otherwise.
+// Unlike Build, Lint does not validate d.
+func Lint(d DSN) Warnings {
+	if l, ok := d.(Linter); ok {
+		return l.Lint()
+	}
+
+	return nil
+}
+
+// LintPassword returns a warning when password is set but shorter than MinPasswordLength.
+func LintPassword(password string) []Warning {
+	if password == "" || len(password) >= MinPasswordLength {
+		return nil
+	}
+
+	return []Warning{{
+		Field:    "password",
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("password is shorter than %d characters", MinPasswordLength),
+	}}
+}
@@ -0,0 +1,71 @@
+package dsn
+
+import (
+	"maps"
+	"testing"
+)
+
+type lintingDSN struct {
+	countingDSN
+	warnings []Warning
+}
+
+func (l *lintingDSN) Lint() []Warning {
+	return l.warnings
+}
+
+func TestLint(t *testing.T) {
+	want := Warning{Field: "ssl_mode", Severity: SeverityWarning, Message: "insecure"}
+	got := Lint(&lintingDSN{warnings: []Warning{want}})
+
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("Lint() = %v, want [%v]", got, want)
+	}
+}
+
+func TestLint_NotLinter(t *testing.T) {
+	if got := Lint(&countingDSN{}); got != nil {
+		t.Errorf("Lint() = %v, want nil", got)
+	}
+}
+
+func TestLintPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{name: "empty", password: "", want: 0},
+		{name: "short", password: "secret", want: 1},
+		{name: "long enough", password: "correct-horse-battery", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LintPassword(tt.password); len(got) != tt.want {
+				t.Errorf("LintPassword(%q) = %v, want %d warnings", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWarning_String(t *testing.T) {
+	w := Warning{Field: "password", Severity: SeverityWarning, Message: "too short"}
+	if got, want := w.String(), "warning: password: too short"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWarnings_Fields(t *testing.T) {
+	warnings := Warnings{
+		{Field: "ssl_mode", Severity: SeverityInfo, Message: "prefer verify-full"},
+		{Field: "password", Severity: SeverityWarning, Message: "too short"},
+		{Field: "ssl_mode", Severity: SeverityWarning, Message: "disabled"},
+	}
+
+	got := warnings.Fields()
+	want := map[string]Severity{"ssl_mode": SeverityWarning, "password": SeverityWarning}
+	if !maps.Equal(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
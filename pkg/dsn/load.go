@@ -0,0 +1,50 @@
+package dsn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load decodes the YAML document data into cfg, which must be a pointer to a driver configuration.
+// Keys that match no field are ignored; use LoadStrict to reject them. Load does not validate cfg; Build does.
+func Load(data []byte, cfg DSN) error {
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	return nil
+}
+
+// LoadStrict is like Load but returns an error naming any key that matches no field, so a typo such
+// as "servce_name" fails instead of silently leaving ServiceName empty.
+func LoadStrict(data []byte, cfg DSN) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	return nil
+}
+
+// LoadFromMap decodes m, e.g. a config section already decoded by Viper, into cfg, which must be
+// a pointer to a driver configuration. Keys are matched against the configuration's yaml tags:
+// m is marshaled to YAML and unmarshaled into cfg, so values are converted as they would be when
+// loading the equivalent YAML document. LoadFromMap does not validate cfg; Build does.
+func LoadFromMap(m map[string]any, cfg DSN) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("dsn: failed to encode config map: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("dsn: failed to decode config map: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package dsn
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadMerged deep-merges the overlay YAML document into base and decodes the result into cfg,
+// which must be a pointer to a driver configuration. Scalars and lists in overlay replace those
+// in base, mappings are merged key by key and keys only present in overlay are added.
+// LoadMerged does not validate cfg; Build does.
+func LoadMerged(base, overlay []byte, cfg DSN) error {
+	var b, o map[string]any
+
+	if err := yaml.Unmarshal(base, &b); err != nil {
+		return fmt.Errorf("dsn: failed to parse base config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(overlay, &o); err != nil {
+		return fmt.Errorf("dsn: failed to parse overlay config: %w", err)
+	}
+
+	data, err := yaml.Marshal(mergeMaps(b, o))
+	if err != nil {
+		return fmt.Errorf("dsn: failed to merge config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("dsn: failed to decode merged config: %w", err)
+	}
+
+	return nil
+}
+
+// mergeMaps returns a copy of base with overlay merged into it, recursing into nested mappings.
+func mergeMaps(base, overlay map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range overlay {
+		bm, bok := merged[k].(map[string]any)
+		om, ook := v.(map[string]any)
+		if bok && ook {
+			merged[k] = mergeMaps(bm, om)
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	return merged
+}
@@ -0,0 +1,62 @@
+package dsn_test
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+	"github.com/pperesbr/gokit/pkg/dsn/mongo"
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/redis"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlite"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+)
+
+const testMeta = "meta:\n  owner: team-payments\n  description: primary ledger\n"
+
+func TestAnnotated(t *testing.T) {
+	tests := []struct {
+		driver string
+		cfg    interface {
+			dsn.DSN
+			dsn.Annotated
+		}
+		yaml string
+	}{
+		{driver: "mysql", cfg: &mysql.Config{}, yaml: "host: db\nuser: app\npassword: s3cret\ndatabase: orders\n"},
+		{driver: "postgres", cfg: &postgres.Config{}, yaml: "host: db\nuser: app\npassword: s3cret\ndatabase: orders\n"},
+		{driver: "oracle", cfg: &oracle.StandaloneConfig{}, yaml: "host: db\nuser: app\npassword: s3cret\nservice_name: ORCLPDB1\n"},
+		{driver: "sqlserver", cfg: &sqlserver.Config{}, yaml: "host: db\nuser: sa\npassword: s3cret\ndatabase: crm\n"},
+		{driver: "mongo", cfg: &mongo.Config{}, yaml: "hosts: [db1]\nuser: app\npassword: s3cret\ndatabase: events\n"},
+		{driver: "redis", cfg: &redis.Config{}, yaml: "host: cache\npassword: s3cret\n"},
+		{driver: "sqlite", cfg: &sqlite.Config{}, yaml: "path: /var/lib/app.db\n"},
+	}
+
+	want := map[string]string{"owner": "team-payments", "description": "primary ledger"}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			if err := dsn.LoadStrict([]byte(tt.yaml+testMeta), tt.cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := tt.cfg.Meta(); !maps.Equal(got, want) {
+				t.Errorf("Meta() = %v, want %v", got, want)
+			}
+
+			ds, err := tt.cfg.Build()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, s := range []string{"meta", "owner", "team-payments", "ledger"} {
+				if strings.Contains(ds, s) {
+					t.Errorf("DSN %q contains metadata %q", ds, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*Config)(nil)
+
+// Lint reports best-practice advisories for the configuration: explicitly disabled TLS
+// and a short password.
+func (c *Config) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	if c.TLS != nil && !*c.TLS {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "tls",
+			Severity: dsn.SeverityWarning,
+			Message:  "TLS is disabled",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(c.Password)...)
+}
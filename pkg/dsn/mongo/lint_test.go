@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   map[string]dsn.Severity
+	}{
+		{
+			name:   "default TLS",
+			config: Config{Password: "correct-horse-battery"},
+			want:   map[string]dsn.Severity{},
+		},
+		{
+			name:   "TLS disabled, short password",
+			config: Config{Password: "secret", TLS: pbool(false)},
+			want: map[string]dsn.Severity{
+				"tls":      dsn.SeverityWarning,
+				"password": dsn.SeverityWarning,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
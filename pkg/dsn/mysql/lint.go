@@ -0,0 +1,37 @@
+package mysql
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*Config)(nil)
+
+// Lint reports best-practice advisories for the configuration: cleartext password
+// authentication, multi statements, a missing dial timeout and a short password.
+func (c *Config) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	if c.AllowCleartextPasswords {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "allowCleartextPasswords",
+			Severity: dsn.SeverityWarning,
+			Message:  "cleartext password authentication sends the password unencrypted without TLS",
+		})
+	}
+
+	if c.MultiStatements {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "multi_statements",
+			Severity: dsn.SeverityInfo,
+			Message:  "multi statements make SQL injection more damaging",
+		})
+	}
+
+	if (c.Timeout == nil || *c.Timeout == 0) && (c.TimeoutDuration == nil || *c.TimeoutDuration == 0) {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "timeout",
+			Severity: dsn.SeverityInfo,
+			Message:  "no dial timeout set; connecting may block indefinitely",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(c.Password)...)
+}
@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   map[string]dsn.Severity
+	}{
+		{
+			name: "hardened config",
+			config: Config{
+				Password:        "correct-horse-battery",
+				TimeoutDuration: pduration(5 * time.Second),
+			},
+			want: map[string]dsn.Severity{},
+		},
+		{
+			name: "cleartext passwords, multi statements, no timeout, short password",
+			config: Config{
+				Password:                "secret",
+				AllowCleartextPasswords: true,
+				MultiStatements:         true,
+			},
+			want: map[string]dsn.Severity{
+				"allowCleartextPasswords": dsn.SeverityWarning,
+				"multi_statements":        dsn.SeverityInfo,
+				"timeout":                 dsn.SeverityInfo,
+				"password":                dsn.SeverityWarning,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
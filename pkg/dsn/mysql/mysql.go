@@ -5,16 +5,27 @@ package mysql
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/pperesbr/gokit/pkg/dsn"
 )
 
+// defaultPort is the MySQL port used when Port is not set.
+var defaultPort = dsn.DefaultPort("mysql")
+
 var (
-	_ dsn.DSN = (*Config)(nil)
+	_ dsn.DSN                  = (*Config)(nil)
+	_ dsn.Endpoint             = (*Config)(nil)
+	_ dsn.ParamProvider        = (*Config)(nil)
+	_ dsn.ConnectTimeoutSetter = (*Config)(nil)
+	_ dsn.ConnectionInfo       = (*Config)(nil)
 
 	ErrMysqlHostRequired        = errors.New("mysql: host is required")
+	ErrMysqlInvalidHost         = errors.New("mysql: host contains invalid characters")
 	ErrMysqlUserRequired        = errors.New("mysql: user is required")
 	ErrMysqlPasswordRequired    = errors.New("mysql: password is required")
 	ErrMysqlDatabaseRequired    = errors.New("mysql: database is required")
@@ -22,11 +33,15 @@ var (
 	ErrMysqlTimeoutInvalid      = errors.New("mysql: timeout must be greater than or equal to 0")
 	ErrMysqlReadTimeoutInvalid  = errors.New("mysql: readTimeout must be greater than or equal to 0")
 	ErrMysqlWriteTimeoutInvalid = errors.New("mysql: writeTimeout must be greater than or equal to 0")
+	ErrMysqlCollationMismatch   = errors.New("mysql: collation does not belong to charset")
+	ErrMysqlInvalidSystemVar    = errors.New("mysql: system variable names must contain only letters, digits and underscores")
 )
 
 // Config represents the MySQL database connection configuration.
 // It contains all necessary parameters to build a valid MySQL DSN string.
 type Config struct {
+	dsn.Annotations `yaml:",inline"`
+
 	// Host is the MySQL server hostname or IP address (required).
 	Host string `yaml:"host"`
 	// User is the MySQL username for authentication (required).
@@ -39,6 +54,8 @@ type Config struct {
 	Port int `yaml:"port"`
 	// Charset specifies the character set for the connection (optional).
 	Charset string `yaml:"charset"`
+	// Collation specifies the collation for the connection; it must belong to Charset when both are set (optional).
+	Collation string `yaml:"collation"`
 	// ParseTime determines whether to parse time values to time.Time (optional).
 	ParseTime *bool `yaml:"parseTime"`
 	// Loc specifies the location for time.Time values (optional).
@@ -49,6 +66,30 @@ type Config struct {
 	ReadTimeout *int `yaml:"readTimeout"`
 	// WriteTimeout specifies the I/O write timeout in seconds (optional, must be >= 0).
 	WriteTimeout *int `yaml:"writeTimeout"`
+	// TimeoutDuration specifies the connection timeout as a duration such as "500ms"; ignored when Timeout is set (optional, must be >= 0).
+	TimeoutDuration *time.Duration `yaml:"timeoutDuration"`
+	// ReadTimeoutDuration specifies the I/O read timeout as a duration; ignored when ReadTimeout is set (optional, must be >= 0).
+	ReadTimeoutDuration *time.Duration `yaml:"readTimeoutDuration"`
+	// WriteTimeoutDuration specifies the I/O write timeout as a duration; ignored when WriteTimeout is set (optional, must be >= 0).
+	WriteTimeoutDuration *time.Duration `yaml:"writeTimeoutDuration"`
+	// InterpolateParams enables client-side interpolation of query placeholders (optional).
+	InterpolateParams bool `yaml:"interpolate_params"`
+	// MultiStatements allows multiple statements in a single query (optional).
+	MultiStatements bool `yaml:"multi_statements"`
+	// AllowNativePasswords enables or disables the mysql_native_password authentication method (optional).
+	AllowNativePasswords *bool `yaml:"allowNativePasswords"`
+	// AllowCleartextPasswords allows the cleartext client-side authentication plugin (optional).
+	AllowCleartextPasswords bool `yaml:"allowCleartextPasswords"`
+	// ServerPubKey is the name of a server public key registered with the driver for RSA authentication (optional).
+	ServerPubKey string `yaml:"serverPubKey"`
+	// TLS selects the TLS mode: true, false, skip-verify, preferred or the name of a TLS config
+	// registered with the driver, e.g. by mysqltls.Register (optional).
+	TLS string `yaml:"tls"`
+	// SystemVars sets session system variables such as time_zone or sql_mode, emitted sorted by name.
+	// Values are sent verbatim, so string values must include their SQL quotes, e.g. "'+00:00'" (optional).
+	SystemVars map[string]string `yaml:"system_vars"`
+	// Params are extra driver parameters such as maxAllowedPacket=0, appended after the other params sorted by name (optional).
+	Params map[string]string `yaml:"params"`
 }
 
 // Build constructs and returns a MySQL DSN string from the configuration.
@@ -64,6 +105,10 @@ func (c *Config) Build() (string, error) {
 		params = append(params, fmt.Sprintf("charset=%s", url.QueryEscape(c.Charset)))
 	}
 
+	if c.Collation != "" {
+		params = append(params, fmt.Sprintf("collation=%s", url.QueryEscape(c.Collation)))
+	}
+
 	if c.ParseTime != nil {
 		valueStr := "True"
 
@@ -78,43 +123,140 @@ func (c *Config) Build() (string, error) {
 		params = append(params, fmt.Sprintf("loc=%s", url.QueryEscape(c.Loc)))
 	}
 
-	if c.Timeout != nil {
-		params = append(params, fmt.Sprintf("timeout=%ds", *c.Timeout))
+	if timeout := formatTimeout(c.Timeout, c.TimeoutDuration); timeout != "" {
+		params = append(params, fmt.Sprintf("timeout=%s", timeout))
+	}
+
+	if timeout := formatTimeout(c.ReadTimeout, c.ReadTimeoutDuration); timeout != "" {
+		params = append(params, fmt.Sprintf("readTimeout=%s", timeout))
+	}
+
+	if timeout := formatTimeout(c.WriteTimeout, c.WriteTimeoutDuration); timeout != "" {
+		params = append(params, fmt.Sprintf("writeTimeout=%s", timeout))
+	}
+
+	if c.InterpolateParams {
+		params = append(params, "interpolateParams=true")
+	}
+
+	if c.MultiStatements {
+		params = append(params, "multiStatements=true")
+	}
+
+	if c.AllowNativePasswords != nil {
+		params = append(params, fmt.Sprintf("allowNativePasswords=%t", *c.AllowNativePasswords))
+	}
+
+	if c.AllowCleartextPasswords {
+		params = append(params, "allowCleartextPasswords=true")
 	}
 
-	if c.ReadTimeout != nil {
-		params = append(params, fmt.Sprintf("readTimeout=%ds", *c.ReadTimeout))
+	if c.ServerPubKey != "" {
+		params = append(params, fmt.Sprintf("serverPubKey=%s", url.QueryEscape(c.ServerPubKey)))
 	}
 
-	if c.WriteTimeout != nil {
-		params = append(params, fmt.Sprintf("writeTimeout=%ds", *c.WriteTimeout))
+	if c.TLS != "" {
+		params = append(params, fmt.Sprintf("tls=%s", url.QueryEscape(c.TLS)))
 	}
 
-	dsn := fmt.Sprintf(""+
-		"%s:%s@tcp(%s:%d)/%s",
+	for _, name := range slices.Sorted(maps.Keys(c.SystemVars)) {
+		params = append(params, fmt.Sprintf("%s=%s", name, url.QueryEscape(c.SystemVars[name])))
+	}
+
+	connStr := fmt.Sprintf(""+
+		"%s:%s@tcp(%s)/%s",
 		url.QueryEscape(c.User),
 		url.QueryEscape(c.Password),
-		c.Host,
-		c.Port,
+		dsn.FormatHostPort(c.Host, c.Port),
 		c.Database)
 
 	if len(params) > 0 {
-		dsn = dsn + "?" + strings.Join(params, "&")
+		connStr = connStr + "?" + strings.Join(params, "&")
+	}
+
+	return dsn.AppendParams(connStr, c.ExtraParams(), "&"), nil
+
+}
+
+// String implements fmt.Stringer for the Config, with Password masked by dsn.MaskPassword.
+func (c Config) String() string {
+	return fmt.Sprintf("mysql.Config{Host: %q, User: %q, Password: %q, Database: %q, Port: %d, "+
+		"Charset: %q, Collation: %q, ParseTime: %s, Loc: %q, Timeout: %s, ReadTimeout: %s, WriteTimeout: %s, "+
+		"TimeoutDuration: %s, ReadTimeoutDuration: %s, WriteTimeoutDuration: %s, "+
+		"InterpolateParams: %t, MultiStatements: %t, AllowNativePasswords: %s, AllowCleartextPasswords: %t, ServerPubKey: %q, TLS: %q, "+
+		"SystemVars: %v, Params: %v}",
+		c.Host,
+		c.User,
+		dsn.MaskPassword(c.Password),
+		c.Database,
+		c.Port,
+		c.Charset,
+		c.Collation,
+		dsn.FormatPtr(c.ParseTime),
+		c.Loc,
+		dsn.FormatPtr(c.Timeout),
+		dsn.FormatPtr(c.ReadTimeout),
+		dsn.FormatPtr(c.WriteTimeout),
+		dsn.FormatPtr(c.TimeoutDuration),
+		dsn.FormatPtr(c.ReadTimeoutDuration),
+		dsn.FormatPtr(c.WriteTimeoutDuration),
+		c.InterpolateParams,
+		c.MultiStatements,
+		dsn.FormatPtr(c.AllowNativePasswords),
+		c.AllowCleartextPasswords,
+		c.ServerPubKey,
+		c.TLS,
+		c.SystemVars,
+		c.Params,
+	)
+}
+
+// ExtraParams returns Params.
+func (c *Config) ExtraParams() map[string]string {
+	return c.Params
+}
+
+// SetConnectTimeout sets Timeout to seconds unless Timeout or TimeoutDuration is already set.
+func (c *Config) SetConnectTimeout(seconds int) {
+	if c.Timeout == nil && c.TimeoutDuration == nil {
+		c.Timeout = &seconds
 	}
+}
+
+// HostPort returns the MySQL server host and port, reporting the default port 3306 when Port is zero.
+func (c *Config) HostPort() (string, int) {
+	if c.Port == 0 {
+		return c.Host, defaultPort
+	}
+	return c.Host, c.Port
+}
 
-	return dsn, nil
+// DatabaseName returns Database.
+func (c *Config) DatabaseName() string {
+	return c.Database
+}
 
+// SetHostPort replaces the MySQL server host and port.
+func (c *Config) SetHostPort(host string, port int) {
+	c.Host = host
+	c.Port = port
 }
 
 // validate checks if all required configuration fields are properly set.
-// It ensures Host, User, Password, and Database are not empty.
+// It ensures Host, User, Password, and Database are not empty and Host is a valid hostname or IP address.
 // It also validates Port is within valid range (1-65535), defaulting to 3306 if zero.
-// Timeout values (Timeout, ReadTimeout, WriteTimeout) must be non-negative if provided.
+// Collation must belong to Charset when both are set.
+// Timeout values (Timeout, ReadTimeout, WriteTimeout and their Duration variants) must be non-negative if provided.
+// SystemVars names must contain only letters, digits and underscores.
 func (c *Config) validate() error {
 	if c.Host == "" {
 		return ErrMysqlHostRequired
 	}
 
+	if !dsn.ValidHost(c.Host) {
+		return ErrMysqlInvalidHost
+	}
+
 	if c.User == "" {
 		return ErrMysqlUserRequired
 	}
@@ -128,24 +270,75 @@ func (c *Config) validate() error {
 	}
 
 	if c.Port == 0 {
-		c.Port = 3306
+		c.Port = defaultPort
 	}
 
 	if c.Port < 1 || c.Port > 65535 {
 		return ErrMysqlInvalidPort
 	}
 
-	if c.Timeout != nil && *c.Timeout < 0 {
+	if c.Charset != "" && c.Collation != "" && !collationMatchesCharset(c.Collation, c.Charset) {
+		return ErrMysqlCollationMismatch
+	}
+
+	if (c.Timeout != nil && *c.Timeout < 0) || (c.TimeoutDuration != nil && *c.TimeoutDuration < 0) {
 		return ErrMysqlTimeoutInvalid
 	}
 
-	if c.ReadTimeout != nil && *c.ReadTimeout < 0 {
+	if (c.ReadTimeout != nil && *c.ReadTimeout < 0) || (c.ReadTimeoutDuration != nil && *c.ReadTimeoutDuration < 0) {
 		return ErrMysqlReadTimeoutInvalid
 	}
 
-	if c.WriteTimeout != nil && *c.WriteTimeout < 0 {
+	if (c.WriteTimeout != nil && *c.WriteTimeout < 0) || (c.WriteTimeoutDuration != nil && *c.WriteTimeoutDuration < 0) {
 		return ErrMysqlWriteTimeoutInvalid
 	}
 
+	for name := range c.SystemVars {
+		if !isValidSystemVarName(name) {
+			return ErrMysqlInvalidSystemVar
+		}
+	}
+
 	return nil
 }
+
+// collationMatchesCharset reports whether collation is named after charset, treating utf8 and utf8mb3 as aliases.
+func collationMatchesCharset(collation, charset string) bool {
+	collation = strings.ToLower(collation)
+	charset = strings.ToLower(charset)
+
+	if charset == "utf8" || charset == "utf8mb3" {
+		return strings.HasPrefix(collation, "utf8_") || strings.HasPrefix(collation, "utf8mb3_")
+	}
+
+	return strings.HasPrefix(collation, charset+"_")
+}
+
+// isValidSystemVarName reports whether name is a non-empty MySQL system variable name.
+func isValidSystemVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatTimeout renders a timeout param value, preferring whole seconds over the duration when both are set.
+// It returns an empty string when neither is set.
+func formatTimeout(seconds *int, d *time.Duration) string {
+	if seconds != nil {
+		return fmt.Sprintf("%ds", *seconds)
+	}
+
+	if d != nil {
+		return d.String()
+	}
+
+	return ""
+}
@@ -2,7 +2,14 @@ package mysql
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
 )
 
 func pint(i int) *int {
@@ -13,6 +20,10 @@ func pbool(b bool) *bool {
 	return &b
 }
 
+func pduration(d time.Duration) *time.Duration {
+	return &d
+}
+
 func TestConfig_Build(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -31,6 +42,28 @@ func TestConfig_Build(t *testing.T) {
 			},
 			wantDSN: "root:secret@tcp(localhost:3306)/mydb",
 		},
+		{
+			name: "ipv6 host",
+			config: Config{
+				Host:     "::1",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				Port:     3306,
+			},
+			wantDSN: "root:secret@tcp([::1]:3306)/mydb",
+		},
+		{
+			name: "ipv4 host",
+			config: Config{
+				Host:     "10.0.0.1",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				Port:     3306,
+			},
+			wantDSN: "root:secret@tcp(10.0.0.1:3306)/mydb",
+		},
 		{
 			name: "valid config with extra params",
 			config: Config{
@@ -48,6 +81,242 @@ func TestConfig_Build(t *testing.T) {
 			},
 			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8mb4&parseTime=True&loc=Local&timeout=5s&readTimeout=30s&writeTimeout=30s",
 		},
+		{
+			name: "sub-second durations",
+			config: Config{
+				Host:                 "localhost",
+				User:                 "root",
+				Password:             "secret",
+				Database:             "mydb",
+				TimeoutDuration:      pduration(500 * time.Millisecond),
+				ReadTimeoutDuration:  pduration(1500 * time.Millisecond),
+				WriteTimeoutDuration: pduration(250 * time.Millisecond),
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?timeout=500ms&readTimeout=1.5s&writeTimeout=250ms",
+		},
+		{
+			name: "integer seconds win over duration",
+			config: Config{
+				Host:            "localhost",
+				User:            "root",
+				Password:        "secret",
+				Database:        "mydb",
+				Timeout:         pint(10),
+				TimeoutDuration: pduration(500 * time.Millisecond),
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?timeout=10s",
+		},
+		{
+			name: "interpolate params",
+			config: Config{
+				Host:              "localhost",
+				User:              "root",
+				Password:          "secret",
+				Database:          "mydb",
+				InterpolateParams: true,
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?interpolateParams=true",
+		},
+		{
+			name: "multi statements",
+			config: Config{
+				Host:            "localhost",
+				User:            "root",
+				Password:        "secret",
+				Database:        "mydb",
+				MultiStatements: true,
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?multiStatements=true",
+		},
+		{
+			name: "interpolate params and multi statements with other params",
+			config: Config{
+				Host:              "localhost",
+				User:              "root",
+				Password:          "secret",
+				Database:          "mydb",
+				Charset:           "utf8mb4",
+				ParseTime:         pbool(true),
+				InterpolateParams: true,
+				MultiStatements:   true,
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8mb4&parseTime=True&interpolateParams=true&multiStatements=true",
+		},
+		{
+			name: "allow native passwords",
+			config: Config{
+				Host:                 "localhost",
+				User:                 "root",
+				Password:             "secret",
+				Database:             "mydb",
+				AllowNativePasswords: pbool(true),
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?allowNativePasswords=true",
+		},
+		{
+			name: "disallow native passwords",
+			config: Config{
+				Host:                 "localhost",
+				User:                 "root",
+				Password:             "secret",
+				Database:             "mydb",
+				AllowNativePasswords: pbool(false),
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?allowNativePasswords=false",
+		},
+		{
+			name: "allow cleartext passwords",
+			config: Config{
+				Host:                    "localhost",
+				User:                    "root",
+				Password:                "secret",
+				Database:                "mydb",
+				AllowCleartextPasswords: true,
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?allowCleartextPasswords=true",
+		},
+		{
+			name: "server public key",
+			config: Config{
+				Host:         "localhost",
+				User:         "root",
+				Password:     "secret",
+				Database:     "mydb",
+				ServerPubKey: "mykey",
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?serverPubKey=mykey",
+		},
+		{
+			name: "charset with matching collation",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "secret",
+				Database:  "mydb",
+				Charset:   "utf8mb4",
+				Collation: "utf8mb4_general_ci",
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8mb4&collation=utf8mb4_general_ci",
+		},
+		{
+			name: "utf8 charset with utf8mb3 collation",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "secret",
+				Database:  "mydb",
+				Charset:   "utf8",
+				Collation: "utf8mb3_general_ci",
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8&collation=utf8mb3_general_ci",
+		},
+		{
+			name: "invalid config: collation of another charset",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "secret",
+				Database:  "mydb",
+				Charset:   "latin1",
+				Collation: "utf8mb4_general_ci",
+			},
+			wantError: ErrMysqlCollationMismatch,
+		},
+		{
+			name: "invalid config: collation sharing a charset prefix",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "secret",
+				Database:  "mydb",
+				Charset:   "utf8",
+				Collation: "utf8mb4_bin",
+			},
+			wantError: ErrMysqlCollationMismatch,
+		},
+		{
+			name: "system vars time_zone and sql_mode, sorted",
+			config: Config{
+				Host:     "localhost",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				SystemVars: map[string]string{
+					"time_zone": "'+00:00'",
+					"sql_mode":  "'TRADITIONAL,NO_AUTO_VALUE_ON_ZERO'",
+				},
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?sql_mode=%27TRADITIONAL%2CNO_AUTO_VALUE_ON_ZERO%27&time_zone=%27%2B00%3A00%27",
+		},
+		{
+			name: "system vars after typed params",
+			config: Config{
+				Host:       "localhost",
+				User:       "root",
+				Password:   "secret",
+				Database:   "mydb",
+				Charset:    "utf8mb4",
+				SystemVars: map[string]string{"autocommit": "1"},
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8mb4&autocommit=1",
+		},
+		{
+			name: "passthrough params after typed params and system vars",
+			config: Config{
+				Host:       "localhost",
+				User:       "root",
+				Password:   "secret",
+				Database:   "mydb",
+				Charset:    "utf8mb4",
+				SystemVars: map[string]string{"autocommit": "1"},
+				Params:     map[string]string{"tls": "custom", "maxAllowedPacket": "0"},
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?charset=utf8mb4&autocommit=1&maxAllowedPacket=0&tls=custom",
+		},
+		{
+			name: "tls config name",
+			config: Config{
+				Host:     "localhost",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				TLS:      "compliance",
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?tls=compliance",
+		},
+		{
+			name: "passthrough params only",
+			config: Config{
+				Host:     "localhost",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				Params:   map[string]string{"tls": "skip-verify"},
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?tls=skip-verify",
+		},
+		{
+			name: "invalid system var name",
+			config: Config{
+				Host:       "localhost",
+				User:       "root",
+				Password:   "secret",
+				Database:   "mydb",
+				SystemVars: map[string]string{"time_zone&x": "'+00:00'"},
+			},
+			wantError: ErrMysqlInvalidSystemVar,
+		},
+		{
+			name: "collation without charset",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "secret",
+				Database:  "mydb",
+				Collation: "utf8mb4_unicode_ci",
+			},
+			wantDSN: "root:secret@tcp(localhost:3306)/mydb?collation=utf8mb4_unicode_ci",
+		},
 		{
 			name: "missing host",
 			config: Config{
@@ -58,6 +327,28 @@ func TestConfig_Build(t *testing.T) {
 			},
 			wantError: ErrMysqlHostRequired,
 		},
+		{
+			name: "host breaking out of tcp()",
+			config: Config{
+				Host:     "evil)/other?x=(",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				Port:     3306,
+			},
+			wantError: ErrMysqlInvalidHost,
+		},
+		{
+			name: "host with embedded credentials",
+			config: Config{
+				Host:     "attacker:pw@evil",
+				User:     "root",
+				Password: "secret",
+				Database: "mydb",
+				Port:     3306,
+			},
+			wantError: ErrMysqlInvalidHost,
+		},
 		{
 			name: "missing user",
 			config: Config{
@@ -156,6 +447,39 @@ func TestConfig_Build(t *testing.T) {
 			},
 			wantError: ErrMysqlWriteTimeoutInvalid,
 		},
+		{
+			name: "invalid config: timeout duration with negative value",
+			config: Config{
+				Host:            "localhost",
+				User:            "root",
+				Password:        "secret",
+				Database:        "mydb",
+				TimeoutDuration: pduration(-time.Millisecond),
+			},
+			wantError: ErrMysqlTimeoutInvalid,
+		},
+		{
+			name: "invalid config: read timeout duration with negative value",
+			config: Config{
+				Host:                "localhost",
+				User:                "root",
+				Password:            "secret",
+				Database:            "mydb",
+				ReadTimeoutDuration: pduration(-time.Millisecond),
+			},
+			wantError: ErrMysqlReadTimeoutInvalid,
+		},
+		{
+			name: "invalid config: write timeout duration with negative value",
+			config: Config{
+				Host:                 "localhost",
+				User:                 "root",
+				Password:             "secret",
+				Database:             "mydb",
+				WriteTimeoutDuration: pduration(-time.Millisecond),
+			},
+			wantError: ErrMysqlWriteTimeoutInvalid,
+		},
 	}
 
 	for _, tt := range tests {
@@ -181,3 +505,149 @@ func TestConfig_Build(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		contains []string
+	}{
+		{
+			name: "password is masked",
+			config: Config{
+				Host:     "localhost",
+				User:     "root",
+				Password: "s3cr3t-p4ss",
+				Database: "mydb",
+				Port:     3306,
+			},
+			contains: []string{`Host: "localhost"`, `User: "root"`, `Password: "****"`, `Database: "mydb"`, "Port: 3306"},
+		},
+		{
+			name: "optional fields are rendered",
+			config: Config{
+				Host:      "localhost",
+				User:      "root",
+				Password:  "s3cr3t-p4ss",
+				Database:  "mydb",
+				ParseTime: pbool(true),
+				Timeout:   pint(5),
+			},
+			contains: []string{"ParseTime: true", "Timeout: 5", "ReadTimeout: <nil>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, out := range []string{tt.config.String(), fmt.Sprintf("%v", tt.config), fmt.Sprintf("%+v", &tt.config)} {
+				for _, want := range tt.contains {
+					if !strings.Contains(out, want) {
+						t.Errorf("output %s does not contain %s", out, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_HostPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		wantHost string
+		wantPort int
+	}{
+		{
+			name:     "explicit port",
+			config:   Config{Host: "db.internal", Port: 40001},
+			wantHost: "db.internal",
+			wantPort: 40001,
+		},
+		{
+			name:     "default port",
+			config:   Config{Host: "db.internal"},
+			wantHost: "db.internal",
+			wantPort: 3306,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, port := tt.config.HostPort()
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("HostPort() = %s, %d, want %s, %d", host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestConfig_SetHostPort(t *testing.T) {
+	config := Config{
+		Host:     "db.internal",
+		User:     "root",
+		Password: "secret",
+		Database: "mydb",
+	}
+
+	config.SetHostPort("127.0.0.1", 40000)
+
+	got, err := config.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "root:secret@tcp(127.0.0.1:40000)/mydb" {
+		t.Errorf("Build() = %s, want %s", got, "root:secret@tcp(127.0.0.1:40000)/mydb")
+	}
+}
+
+func TestConfig_DurationFromYAML(t *testing.T) {
+	var config Config
+	if err := yaml.Unmarshal([]byte("timeoutDuration: 500ms\nreadTimeoutDuration: 2s\n"), &config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.TimeoutDuration == nil || *config.TimeoutDuration != 500*time.Millisecond {
+		t.Errorf("TimeoutDuration = %v, want 500ms", dsn.FormatPtr(config.TimeoutDuration))
+	}
+
+	if config.ReadTimeoutDuration == nil || *config.ReadTimeoutDuration != 2*time.Second {
+		t.Errorf("ReadTimeoutDuration = %v, want 2s", dsn.FormatPtr(config.ReadTimeoutDuration))
+	}
+}
+
+func TestConfig_SetConnectTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		timeout         *int
+		timeoutDuration *time.Duration
+		want            string
+	}{
+		{name: "unset", want: "timeout=10s"},
+		{name: "timeout set", timeout: pint(3), want: "timeout=3s"},
+		{name: "timeout duration set", timeoutDuration: pduration(500 * time.Millisecond), want: "timeout=500ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{
+				Host:            "localhost",
+				User:            "root",
+				Password:        "secret",
+				Database:        "app",
+				Timeout:         tt.timeout,
+				TimeoutDuration: tt.timeoutDuration,
+			}
+			c.SetConnectTimeout(10)
+
+			got, err := c.Build()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Build() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
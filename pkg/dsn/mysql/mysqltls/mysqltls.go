@@ -0,0 +1,106 @@
+// Package mysqltls registers TLS configurations with github.com/go-sql-driver/mysql for use in
+// mysql.Config.TLS. It is kept apart from the mysql package, which imports no SQL driver.
+package mysqltls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+var (
+	ErrMysqlTLSNameRequired       = errors.New("mysqltls: name is required")
+	ErrMysqlTLSReservedName       = errors.New("mysqltls: name must not be true, false, skip-verify or preferred")
+	ErrMysqlTLSInvalidMinVersion  = errors.New("mysqltls: invalid min_version value, valid values are: 1.0, 1.1, 1.2, 1.3")
+	ErrMysqlTLSClientCertRequired = errors.New("mysqltls: client_cert_file and client_key_file must be set together")
+	ErrMysqlTLSInvalidCACert      = errors.New("mysqltls: ca_cert_file contains no PEM certificates")
+)
+
+// tlsVersions maps the accepted MinVersion values to their crypto/tls constants.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Params describes a TLS configuration for MySQL connections.
+type Params struct {
+	// MinVersion is the minimum TLS version: 1.0, 1.1, 1.2 or 1.3 (optional, defaults to the crypto/tls default).
+	MinVersion string `yaml:"min_version"`
+	// ServerName is the host name verified against the server certificate (optional, defaults to the DSN host).
+	ServerName string `yaml:"server_name"`
+	// CACertFile is a PEM file with the certificate authorities trusted for the server certificate
+	// (optional, defaults to the system roots).
+	CACertFile string `yaml:"ca_cert_file"`
+	// ClientCertFile is a PEM client certificate for mutual TLS; requires ClientKeyFile (optional).
+	ClientCertFile string `yaml:"client_cert_file"`
+	// ClientKeyFile is the PEM private key of ClientCertFile (optional).
+	ClientKeyFile string `yaml:"client_key_file"`
+	// InsecureSkipVerify disables verification of the server certificate. Development only (optional).
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// TLSConfig validates the params, loads the certificate files and returns the resulting *tls.Config.
+func (p Params) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         p.ServerName,
+		InsecureSkipVerify: p.InsecureSkipVerify,
+	}
+
+	if p.MinVersion != "" {
+		version, ok := tlsVersions[p.MinVersion]
+		if !ok {
+			return nil, ErrMysqlTLSInvalidMinVersion
+		}
+		cfg.MinVersion = version
+	}
+
+	if p.CACertFile != "" {
+		pem, err := os.ReadFile(p.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("mysqltls: failed to read ca_cert_file: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrMysqlTLSInvalidCACert
+		}
+	}
+
+	if (p.ClientCertFile == "") != (p.ClientKeyFile == "") {
+		return nil, ErrMysqlTLSClientCertRequired
+	}
+
+	if p.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCertFile, p.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("mysqltls: failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Register builds the TLS configuration described by p and registers it with the MySQL driver
+// under name, which is then set as mysql.Config.TLS. Registering a name again replaces it.
+func Register(name string, p Params) error {
+	switch name {
+	case "":
+		return ErrMysqlTLSNameRequired
+	case "true", "false", "skip-verify", "preferred":
+		return ErrMysqlTLSReservedName
+	}
+
+	cfg, err := p.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	return driver.RegisterTLSConfig(name, cfg)
+}
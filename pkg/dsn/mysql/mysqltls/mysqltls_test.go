@@ -0,0 +1,161 @@
+package mysqltls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files and returns their paths.
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mysql.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestParams_TLSConfig(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+
+	cfg, err := Params{
+		MinVersion:     "1.3",
+		ServerName:     "mysql.internal",
+		CACertFile:     certFile,
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	}.TLSConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x, want TLS 1.3", cfg.MinVersion)
+	}
+	if cfg.ServerName != "mysql.internal" {
+		t.Errorf("ServerName = %q, want mysql.internal", cfg.ServerName)
+	}
+	if cfg.RootCAs == nil {
+		t.Error("expected RootCAs to be loaded from CACertFile")
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Errorf("expected 1 client certificate, got %d", len(cfg.Certificates))
+	}
+	if cfg.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify to be false")
+	}
+}
+
+func TestParams_TLSConfig_Errors(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr error
+	}{
+		{name: "invalid min version", params: Params{MinVersion: "1.4"}, wantErr: ErrMysqlTLSInvalidMinVersion},
+		{name: "client cert without key", params: Params{ClientCertFile: certFile}, wantErr: ErrMysqlTLSClientCertRequired},
+		{name: "key as ca", params: Params{CACertFile: keyFile}, wantErr: ErrMysqlTLSInvalidCACert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.params.TLSConfig(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	certFile, _ := writeTestCert(t)
+
+	if err := Register("gokit-test", Params{MinVersion: "1.2", CACertFile: certFile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { driver.DeregisterTLSConfig("gokit-test") })
+
+	ds, err := (&mysql.Config{
+		Host:     "mysql.internal",
+		User:     "app",
+		Password: "secret",
+		Database: "orders",
+		TLS:      "gokit-test",
+	}).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := driver.ParseDSN(ds)
+	if err != nil {
+		t.Fatalf("driver rejected %q: %v", ds, err)
+	}
+
+	if parsed.TLS == nil || parsed.TLS.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected the registered TLS config with TLS 1.2 minimum, got %+v", parsed.TLS)
+	}
+}
+
+func TestRegister_InvalidName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr error
+	}{
+		{name: "", wantErr: ErrMysqlTLSNameRequired},
+		{name: "skip-verify", wantErr: ErrMysqlTLSReservedName},
+	}
+
+	for _, tt := range tests {
+		if err := Register(tt.name, Params{}); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Register(%q): got %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
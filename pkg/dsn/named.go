@@ -0,0 +1,92 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrDriverRequired is returned by LoadNamed for a database block without a driver.
+	ErrDriverRequired = errors.New("dsn: driver is required")
+
+	// ErrNilConfig is returned by VerifyNewConfig when newConfig returns no configuration and no error.
+	ErrNilConfig = errors.New("dsn: newConfig returned a nil config")
+)
+
+// Named is a configuration loaded by LoadNamed together with the driver named in its block, so it can be
+// passed on to TestConnection or sqldb.Open.
+type Named struct {
+	Driver string
+	DSN    DSN
+}
+
+// LoadNamed decodes the named database blocks under the top-level databases key of the YAML document
+// data, each of which names its driver:
+//
+//	databases:
+//	  analytics: {driver: postgres, host: pg.internal, ...}
+//	  reporting: {driver: mysql, host: mysql.internal, ...}
+//
+// newConfig returns an empty configuration for a driver, or an error such as ErrUnknownDriver for a
+// driver it does not support. Blocks are decoded like Load and are not validated; Build does.
+func LoadNamed(data []byte, newConfig func(driver string) (DSN, error)) (map[string]Named, error) {
+	var doc struct {
+		Databases map[string]yaml.Node `yaml:"databases"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	named := make(map[string]Named, len(doc.Databases))
+	for name, node := range doc.Databases {
+		var block struct {
+			Driver string `yaml:"driver"`
+		}
+		if err := node.Decode(&block); err != nil {
+			return nil, fmt.Errorf("dsn: failed to parse database %s: %w", name, err)
+		}
+
+		if block.Driver == "" {
+			return nil, fmt.Errorf("%w: database %s", ErrDriverRequired, name)
+		}
+
+		cfg, err := newConfig(block.Driver)
+		if err != nil {
+			return nil, fmt.Errorf("database %s: %w", name, err)
+		}
+
+		if err := node.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("dsn: failed to parse database %s: %w", name, err)
+		}
+
+		named[name] = Named{Driver: block.Driver, DSN: cfg}
+	}
+
+	return named, nil
+}
+
+// VerifyNewConfig checks at startup that newConfig, as passed to LoadNamed or LoadFromKey, is wired correctly for
+// driver: it must return a non-nil configuration into which the minimal sample config decodes like LoadStrict, so a
+// function returning another driver's configuration fails on the sample's keys. The configuration is not validated.
+func VerifyNewConfig(driver string, sample []byte, newConfig func(driver string) (DSN, error)) error {
+	if driver == "" {
+		return ErrDriverRequired
+	}
+
+	cfg, err := newConfig(driver)
+	if err != nil {
+		return err
+	}
+
+	if cfg == nil {
+		return fmt.Errorf("%w: %s", ErrNilConfig, driver)
+	}
+
+	if err := LoadStrict(sample, cfg); err != nil {
+		return fmt.Errorf("%s sample: %w", driver, err)
+	}
+
+	return nil
+}
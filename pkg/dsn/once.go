@@ -0,0 +1,27 @@
+package dsn
+
+import "sync"
+
+// onceDSN caches the result of the first Build call of the wrapped DSN.
+type onceDSN struct {
+	dsn  DSN
+	once sync.Once
+	ds   string
+	err  error
+}
+
+// BuildOnce wraps d so that Build validates and builds the connection string only once.
+// The first result, including an error, is returned by every later call.
+// Mutating d after the first Build call has undefined results.
+func BuildOnce(d DSN) DSN {
+	return &onceDSN{dsn: d}
+}
+
+// Build returns the cached result of the wrapped DSN's first Build call.
+func (o *onceDSN) Build() (string, error) {
+	o.once.Do(func() {
+		o.ds, o.err = o.dsn.Build()
+	})
+
+	return o.ds, o.err
+}
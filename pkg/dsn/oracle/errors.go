@@ -6,6 +6,9 @@ var (
 	// ErrOracleHostRequired is returned when the host parameter is missing from the DSN.
 	ErrOracleHostRequired = errors.New("oracle: host is required")
 
+	// ErrOracleInvalidHost is returned when the host parameter contains characters not allowed in a hostname or IP address.
+	ErrOracleInvalidHost = errors.New("oracle: host contains invalid characters")
+
 	// ErrOracleUserRequired is returned when the user parameter is missing from the DSN.
 	ErrOracleUserRequired = errors.New("oracle: user is required")
 
@@ -23,4 +26,10 @@ var (
 
 	// ErrOracleTimeoutInvalid is returned when the timeout parameter is negative.
 	ErrOracleTimeoutInvalid = errors.New("oracle: timeout must be greater than or equal to 0")
+
+	// ErrOracleInvalidInstanceName is returned when the instance_name parameter is not a plain identifier.
+	ErrOracleInvalidInstanceName = errors.New("oracle: instance_name must be a plain identifier")
+
+	// ErrOracleInvalidCID is returned when cid_program or cid_user contains parentheses, '=', '\' or control characters.
+	ErrOracleInvalidCID = errors.New("oracle: cid_program and cid_user must not contain parentheses, '=', '\\' or control characters")
 )
@@ -0,0 +1,21 @@
+package oracle
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*StandaloneConfig)(nil)
+
+// Lint reports best-practice advisories for the configuration: a missing connection
+// timeout and a short password.
+func (s *StandaloneConfig) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	if s.ConnectionTimeout == nil || *s.ConnectionTimeout == 0 {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "connection_timeout",
+			Severity: dsn.SeverityInfo,
+			Message:  "no connection timeout set; connecting may block indefinitely",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(s.Password)...)
+}
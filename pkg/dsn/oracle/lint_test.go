@@ -0,0 +1,44 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestStandaloneConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config StandaloneConfig
+		want   map[string]dsn.Severity
+	}{
+		{
+			name:   "hardened config",
+			config: StandaloneConfig{Password: "correct-horse-battery", ConnectionTimeout: pint(10)},
+			want:   map[string]dsn.Severity{},
+		},
+		{
+			name:   "no timeout, short password",
+			config: StandaloneConfig{Password: "secret"},
+			want: map[string]dsn.Severity{
+				"connection_timeout": dsn.SeverityInfo,
+				"password":           dsn.SeverityWarning,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
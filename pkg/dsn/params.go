@@ -0,0 +1,32 @@
+package dsn
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// AppendParams appends params to base as URL query parameters sorted by name and joined with sep, usually "&", and
+// returns the result. The query is started with "?" when base has none, e.g. "postgres://db/app?a=b&c=d".
+func AppendParams(base string, params map[string]string, sep string) string {
+	if len(params) == 0 {
+		return base
+	}
+
+	names := slices.Sorted(maps.Keys(params))
+	pairs := make([]string, len(names))
+
+	for i, name := range names {
+		pairs[i] = url.QueryEscape(name) + "=" + url.QueryEscape(params[name])
+	}
+
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, sep):
+		base += sep
+	}
+
+	return base + strings.Join(pairs, sep)
+}
@@ -0,0 +1,49 @@
+package dsn
+
+import "testing"
+
+func TestAppendParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		params map[string]string
+		sep    string
+		want   string
+	}{
+		{
+			name: "no params",
+			base: "postgres://db/app",
+			sep:  "&",
+			want: "postgres://db/app",
+		},
+		{
+			name:   "url without query",
+			base:   "postgres://db/app",
+			params: map[string]string{"b": "2", "a": "1"},
+			sep:    "&",
+			want:   "postgres://db/app?a=1&b=2",
+		},
+		{
+			name:   "url with query",
+			base:   "sqlserver://db?database=app",
+			params: map[string]string{"app name": "billing&co"},
+			sep:    "&",
+			want:   "sqlserver://db?database=app&app+name=billing%26co",
+		},
+		{
+			name:   "url ending with separator",
+			base:   "user:pass@tcp(db:3306)/app?",
+			params: map[string]string{"tls": "custom"},
+			sep:    "&",
+			want:   "user:pass@tcp(db:3306)/app?tls=custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AppendParams(tt.base, tt.params, tt.sep); got != tt.want {
+				t.Errorf("AppendParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
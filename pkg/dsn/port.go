@@ -0,0 +1,16 @@
+package dsn
+
+// defaultPorts maps driver names to the port their servers listen on by default.
+var defaultPorts = map[string]int{
+	"mongo":     27017,
+	"mysql":     3306,
+	"oracle":    1521,
+	"postgres":  5432,
+	"redis":     6379,
+	"sqlserver": 1433,
+}
+
+// DefaultPort returns the default server port for driver, or 0 when the driver is unknown or has no port.
+func DefaultPort(driver string) int {
+	return defaultPorts[driver]
+}
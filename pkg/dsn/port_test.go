@@ -0,0 +1,27 @@
+package dsn
+
+import "testing"
+
+func TestDefaultPort(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   int
+	}{
+		{driver: "mongo", want: 27017},
+		{driver: "mysql", want: 3306},
+		{driver: "oracle", want: 1521},
+		{driver: "postgres", want: 5432},
+		{driver: "redis", want: 6379},
+		{driver: "sqlserver", want: 1433},
+		{driver: "sqlite", want: 0},
+		{driver: "unknown", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			if got := DefaultPort(tt.driver); got != tt.want {
+				t.Errorf("DefaultPort(%q) = %d, want %d", tt.driver, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*Config)(nil)
+
+// Lint reports best-practice advisories for the configuration: an unencrypted or
+// unverified sslmode for network hosts, a missing connect timeout and a short password.
+func (c *Config) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	// TLS is never used over Unix-domain sockets.
+	if !isSocketDir(c.Host) {
+		switch c.SSLMode {
+		case "", "disable", "allow", "prefer":
+			warnings = append(warnings, dsn.Warning{
+				Field:    "ssl_mode",
+				Severity: dsn.SeverityWarning,
+				Message:  "sslmode does not require TLS; use require, verify-ca or verify-full",
+			})
+		case "require":
+			warnings = append(warnings, dsn.Warning{
+				Field:    "ssl_mode",
+				Severity: dsn.SeverityInfo,
+				Message:  "sslmode=require does not verify the server certificate; prefer verify-full",
+			})
+		}
+	}
+
+	if c.ConnectTimeout == nil || *c.ConnectTimeout == 0 {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "connection_timeout",
+			Severity: dsn.SeverityInfo,
+			Message:  "no connect timeout set; connecting may block indefinitely",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(c.Password)...)
+}
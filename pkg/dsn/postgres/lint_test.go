@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   map[string]dsn.Severity
+	}{
+		{
+			name: "hardened config",
+			config: Config{
+				Password:       "correct-horse-battery",
+				SSLMode:        "verify-full",
+				ConnectTimeout: pint(10),
+			},
+			want: map[string]dsn.Severity{},
+		},
+		{
+			name:   "sslmode disable, no timeout, short password",
+			config: Config{Password: "secret", SSLMode: "disable"},
+			want: map[string]dsn.Severity{
+				"ssl_mode":           dsn.SeverityWarning,
+				"connection_timeout": dsn.SeverityInfo,
+				"password":           dsn.SeverityWarning,
+			},
+		},
+		{
+			name:   "sslmode require",
+			config: Config{Password: "correct-horse-battery", SSLMode: "require", ConnectTimeout: pint(5)},
+			want:   map[string]dsn.Severity{"ssl_mode": dsn.SeverityInfo},
+		},
+		{
+			name:   "unix socket without sslmode",
+			config: Config{Host: "/var/run/postgresql", Password: "correct-horse-battery", ConnectTimeout: pint(5)},
+			want:   map[string]dsn.Severity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
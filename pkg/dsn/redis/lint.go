@@ -0,0 +1,28 @@
+package redis
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*Config)(nil)
+
+// Lint reports best-practice advisories for the configuration: a missing password,
+// a password sent without TLS and a short password.
+func (c *Config) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	switch {
+	case c.Password == "":
+		warnings = append(warnings, dsn.Warning{
+			Field:    "password",
+			Severity: dsn.SeverityInfo,
+			Message:  "no password set; the server must not be reachable by untrusted clients",
+		})
+	case !c.TLS:
+		warnings = append(warnings, dsn.Warning{
+			Field:    "tls",
+			Severity: dsn.SeverityWarning,
+			Message:  "the password is sent without TLS",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(c.Password)...)
+}
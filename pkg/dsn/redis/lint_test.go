@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   map[string]dsn.Severity
+	}{
+		{
+			name:   "password over TLS",
+			config: Config{Password: "correct-horse-battery", TLS: true},
+			want:   map[string]dsn.Severity{},
+		},
+		{
+			name:   "no password",
+			config: Config{},
+			want:   map[string]dsn.Severity{"password": dsn.SeverityInfo},
+		},
+		{
+			name:   "short password without TLS",
+			config: Config{Password: "secret"},
+			want: map[string]dsn.Severity{
+				"tls":      dsn.SeverityWarning,
+				"password": dsn.SeverityWarning,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
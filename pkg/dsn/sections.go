@@ -0,0 +1,141 @@
+package dsn
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrUnknownSection is returned by LoadSectionsStrict for a top-level section with no configuration.
+	ErrUnknownSection = errors.New("dsn: unknown config section")
+
+	// ErrKeyRequired is returned by LoadFromKey for an empty top-level key.
+	ErrKeyRequired = errors.New("dsn: key is required")
+
+	// ErrSectionNotFound is returned by LoadFromKey when the document has no section under the key.
+	ErrSectionNotFound = errors.New("dsn: config section not found")
+
+	// ErrDriverNotDetected is returned by DetectDriver when no top-level key names one of the drivers.
+	ErrDriverNotDetected = errors.New("dsn: no known driver section found")
+)
+
+// LoadSections decodes every top-level section of the YAML document data into the configuration
+// given for its key in cfgs, e.g. the "orders" and "billing" databases of one file:
+//
+//	pg, my := &postgres.Config{}, &mysql.Config{}
+//	err := dsn.LoadSections(data, map[string]dsn.DSN{"orders": pg, "billing": my})
+//
+// Sections without a configuration are ignored, and so are keys that match no field, like Load.
+// Configurations without a section are left unchanged. LoadSections does not validate them; Build does.
+func LoadSections(data []byte, cfgs map[string]DSN) error {
+	return loadSections(data, cfgs, false)
+}
+
+// LoadSectionsStrict is like LoadSections but returns ErrUnknownSection for a section without a
+// configuration and decodes each section like LoadStrict.
+func LoadSectionsStrict(data []byte, cfgs map[string]DSN) error {
+	return loadSections(data, cfgs, true)
+}
+
+// LoadFromKey decodes the top-level section key of the YAML document data into a new configuration for driver,
+// whatever the key is named, e.g. a postgres database under primary_db:
+//
+//	cfg, err := dsn.LoadFromKey("primary_db", "postgres", data, newConfig)
+//
+// newConfig returns an empty configuration for a driver, as in LoadNamed. The section is decoded like Load and is
+// not validated; Build does.
+func LoadFromKey(key, driver string, data []byte, newConfig func(driver string) (DSN, error)) (DSN, error) {
+	if key == "" {
+		return nil, ErrKeyRequired
+	}
+
+	if driver == "" {
+		return nil, ErrDriverRequired
+	}
+
+	cfg, err := newConfig(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	node, ok := sections[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSectionNotFound, key)
+	}
+
+	if err := node.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("dsn: failed to parse section %s: %w", key, err)
+	}
+
+	return cfg, nil
+}
+
+// DetectDriver returns the first top-level key of the YAML document data, in document order, that is one of drivers,
+// e.g. "postgres" for a document with a postgres: section. It only reads the keys and decodes no configuration. It
+// returns ErrDriverNotDetected when no key matches.
+func DetectDriver(data []byte, drivers []string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", ErrDriverNotDetected
+	}
+
+	keys := doc.Content[0].Content
+	for i := 0; i < len(keys); i += 2 {
+		if slices.Contains(drivers, keys[i].Value) {
+			return keys[i].Value, nil
+		}
+	}
+
+	return "", ErrDriverNotDetected
+}
+
+// loadSections implements LoadSections and, when strict is set, LoadSectionsStrict.
+func loadSections(data []byte, cfgs map[string]DSN, strict bool) error {
+	var sections map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return fmt.Errorf("dsn: failed to parse config: %w", err)
+	}
+
+	// Sections are decoded in a fixed order so the reported error does not depend on map iteration.
+	for _, name := range slices.Sorted(maps.Keys(sections)) {
+		cfg, ok := cfgs[name]
+		if !ok {
+			if strict {
+				return fmt.Errorf("%w: %s", ErrUnknownSection, name)
+			}
+			continue
+		}
+
+		node := sections[name]
+		if !strict {
+			if err := node.Decode(cfg); err != nil {
+				return fmt.Errorf("dsn: failed to parse section %s: %w", name, err)
+			}
+			continue
+		}
+
+		section, err := yaml.Marshal(&node)
+		if err != nil {
+			return fmt.Errorf("dsn: failed to parse section %s: %w", name, err)
+		}
+
+		if err := LoadStrict(section, cfg); err != nil {
+			return fmt.Errorf("section %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
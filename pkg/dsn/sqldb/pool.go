@@ -0,0 +1,41 @@
+package sqldb
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolConfig holds the database/sql connection pool settings. It can be decoded from the
+// same YAML block as a DSN configuration. Zero values leave the database/sql defaults.
+type PoolConfig struct {
+	// MaxOpen is passed to sql.DB.SetMaxOpenConns.
+	MaxOpen int `yaml:"max_open_conns"`
+
+	// MaxIdle is passed to sql.DB.SetMaxIdleConns.
+	MaxIdle int `yaml:"max_idle_conns"`
+
+	// MaxLifetime is passed to sql.DB.SetConnMaxLifetime.
+	MaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+
+	// MaxIdleTime is passed to sql.DB.SetConnMaxIdleTime.
+	MaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// Apply sets the non-zero pool settings on db.
+func (p PoolConfig) Apply(db *sql.DB) {
+	if p.MaxOpen != 0 {
+		db.SetMaxOpenConns(p.MaxOpen)
+	}
+
+	if p.MaxIdle != 0 {
+		db.SetMaxIdleConns(p.MaxIdle)
+	}
+
+	if p.MaxLifetime != 0 {
+		db.SetConnMaxLifetime(p.MaxLifetime)
+	}
+
+	if p.MaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(p.MaxIdleTime)
+	}
+}
@@ -0,0 +1,63 @@
+package sqldb
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPoolConfig_YAML(t *testing.T) {
+	data := []byte(`
+host: localhost
+max_open_conns: 20
+max_idle_conns: 5
+conn_max_lifetime: 30m
+conn_max_idle_time: 90s
+`)
+
+	var pool PoolConfig
+	if err := yaml.Unmarshal(data, &pool); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := PoolConfig{MaxOpen: 20, MaxIdle: 5, MaxLifetime: 30 * time.Minute, MaxIdleTime: 90 * time.Second}
+	if pool != want {
+		t.Errorf("PoolConfig = %+v, want %+v", pool, want)
+	}
+}
+
+func TestOpenWithPool(t *testing.T) {
+	registerStub(t, "postgres")
+
+	db, err := OpenWithPool("postgres", staticDSN{ds: "postgres://localhost/db"}, PoolConfig{MaxOpen: 3, MaxIdle: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("MaxOpenConnections = %d, want 3", got)
+	}
+}
+
+func TestPoolConfig_Apply(t *testing.T) {
+	registerStub(t, "mysql")
+
+	db, err := Open("mysql", staticDSN{ds: "user:pass@tcp(localhost:3306)/db"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	PoolConfig{MaxOpen: 7}.Apply(db)
+	if got := db.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("MaxOpenConnections = %d, want 7", got)
+	}
+
+	// Zero values keep the current settings.
+	PoolConfig{}.Apply(db)
+	if got := db.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("MaxOpenConnections after zero Apply = %d, want 7", got)
+	}
+}
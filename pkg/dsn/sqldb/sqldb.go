@@ -0,0 +1,37 @@
+// Package sqldb opens database/sql handles from dsn configurations. Like the dsn package it
+// imports no SQL driver: callers register the driver they need with a blank import.
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+// Open builds d and opens it with the database/sql driver mapped to driver by
+// dsn.SQLDriverName. The returned *sql.DB is not pinged.
+func Open(driver string, d dsn.DSN) (*sql.DB, error) {
+	name := dsn.SQLDriverName(driver)
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", dsn.ErrUnknownDriver, driver)
+	}
+
+	ds, err := d.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.Open(name, ds)
+}
+
+// OpenWithPool is like Open and applies pool to the returned *sql.DB.
+func OpenWithPool(driver string, d dsn.DSN, pool PoolConfig) (*sql.DB, error) {
+	db, err := Open(driver, d)
+	if err != nil {
+		return nil, err
+	}
+
+	pool.Apply(db)
+	return db, nil
+}
@@ -0,0 +1,137 @@
+// Package sqlite provides SQLite DSN (Data Source Name) configuration and building functionality.
+// It implements the dsn.DSN interface to construct connection strings in the file: URI format.
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+// memoryPath is the special path that opens an in-memory database.
+const memoryPath = ":memory:"
+
+var (
+	_ dsn.DSN = (*Config)(nil)
+
+	// validModes contains the accepted values for the mode parameter.
+	validModes = map[string]struct{}{
+		"ro":     {},
+		"rw":     {},
+		"rwc":    {},
+		"memory": {},
+	}
+
+	// validCaches contains the accepted values for the cache parameter.
+	validCaches = map[string]struct{}{
+		"shared":  {},
+		"private": {},
+	}
+
+	// validJournalModes contains the accepted values for the _journal_mode parameter.
+	validJournalModes = map[string]struct{}{
+		"DELETE":   {},
+		"TRUNCATE": {},
+		"PERSIST":  {},
+		"MEMORY":   {},
+		"WAL":      {},
+		"OFF":      {},
+	}
+
+	ErrSqlitePathRequired       = errors.New("sqlite: path is required")
+	ErrSqliteInvalidPath        = errors.New("sqlite: path must not contain '?' or '#'")
+	ErrSqliteInvalidMode        = errors.New("sqlite: invalid mode, valid values are: ro, rw, rwc, memory")
+	ErrSqliteInvalidCache       = errors.New("sqlite: invalid cache, valid values are: shared, private")
+	ErrSqliteInvalidJournalMode = errors.New("sqlite: invalid journalMode, valid values are: DELETE, TRUNCATE, PERSIST, MEMORY, WAL, OFF")
+	ErrSqliteBusyTimeoutInvalid = errors.New("sqlite: busyTimeout must be greater than or equal to 0")
+)
+
+// Config represents the SQLite database connection configuration.
+// It contains all necessary parameters to build a valid file: URI DSN string.
+type Config struct {
+	dsn.Annotations `yaml:",inline"`
+
+	// Path is the database file path, or ":memory:" for an in-memory database
+	// (required unless Mode is "memory").
+	Path string `yaml:"path"`
+	// Mode is the access mode: ro, rw, rwc or memory (optional).
+	Mode string `yaml:"mode"`
+	// Cache is the cache mode: shared or private (optional).
+	Cache string `yaml:"cache"`
+	// JournalMode is the journal mode: DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF (optional).
+	JournalMode string `yaml:"journalMode"`
+	// BusyTimeout specifies how long to wait on a locked database in milliseconds (optional, must be >= 0).
+	BusyTimeout *int `yaml:"busyTimeout"`
+}
+
+// Build constructs and returns a SQLite DSN string from the configuration.
+// It validates the configuration first and returns an error if validation fails.
+// The returned DSN string follows the format: file:path?param1=value1&param2=value2
+func (c *Config) Build() (string, error) {
+	if err := c.validate(); err != nil {
+		return "", err
+	}
+
+	path := c.Path
+	if path == "" {
+		path = memoryPath
+	}
+
+	var params []string
+
+	if c.Mode != "" {
+		params = append(params, fmt.Sprintf("mode=%s", c.Mode))
+	}
+
+	if c.Cache != "" {
+		params = append(params, fmt.Sprintf("cache=%s", c.Cache))
+	}
+
+	if c.JournalMode != "" {
+		params = append(params, fmt.Sprintf("_journal_mode=%s", c.JournalMode))
+	}
+
+	if c.BusyTimeout != nil {
+		params = append(params, fmt.Sprintf("_busy_timeout=%d", *c.BusyTimeout))
+	}
+
+	ds := "file:" + path
+	if len(params) > 0 {
+		ds += "?" + strings.Join(params, "&")
+	}
+
+	return ds, nil
+}
+
+// validate checks if all required configuration fields are properly set.
+// It ensures Path is set unless Mode is "memory" and does not contain URI delimiters.
+// Mode, Cache and JournalMode must be supported values and BusyTimeout must be non-negative if provided.
+func (c *Config) validate() error {
+	if c.Path == "" && c.Mode != "memory" {
+		return ErrSqlitePathRequired
+	}
+
+	if strings.ContainsAny(c.Path, "?#") {
+		return ErrSqliteInvalidPath
+	}
+
+	if _, ok := validModes[c.Mode]; c.Mode != "" && !ok {
+		return ErrSqliteInvalidMode
+	}
+
+	if _, ok := validCaches[c.Cache]; c.Cache != "" && !ok {
+		return ErrSqliteInvalidCache
+	}
+
+	if _, ok := validJournalModes[c.JournalMode]; c.JournalMode != "" && !ok {
+		return ErrSqliteInvalidJournalMode
+	}
+
+	if c.BusyTimeout != nil && *c.BusyTimeout < 0 {
+		return ErrSqliteBusyTimeoutInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+)
+
+func pint(i int) *int {
+	return &i
+}
+
+func TestConfig_Build(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		wantError error
+		wantDSN   string
+	}{
+		{
+			name:    "valid config with no extra params",
+			config:  Config{Path: "/var/lib/app/app.db"},
+			wantDSN: "file:/var/lib/app/app.db",
+		},
+		{
+			name: "valid config with extra params",
+			config: Config{
+				Path:        "app.db",
+				Mode:        "rwc",
+				Cache:       "shared",
+				JournalMode: "WAL",
+				BusyTimeout: pint(5000),
+			},
+			wantDSN: "file:app.db?mode=rwc&cache=shared&_journal_mode=WAL&_busy_timeout=5000",
+		},
+		{
+			name:    "in-memory path",
+			config:  Config{Path: ":memory:", Cache: "shared"},
+			wantDSN: "file::memory:?cache=shared",
+		},
+		{
+			name:    "memory mode without path",
+			config:  Config{Mode: "memory"},
+			wantDSN: "file::memory:?mode=memory",
+		},
+		{
+			name:      "missing path",
+			config:    Config{Cache: "shared"},
+			wantError: ErrSqlitePathRequired,
+		},
+		{
+			name:      "invalid config: path with query delimiter",
+			config:    Config{Path: "app.db?mode=ro"},
+			wantError: ErrSqliteInvalidPath,
+		},
+		{
+			name:      "invalid config: unknown mode",
+			config:    Config{Path: "app.db", Mode: "readonly"},
+			wantError: ErrSqliteInvalidMode,
+		},
+		{
+			name:      "invalid config: unknown cache",
+			config:    Config{Path: "app.db", Cache: "global"},
+			wantError: ErrSqliteInvalidCache,
+		},
+		{
+			name:      "invalid config: unknown journal mode",
+			config:    Config{Path: "app.db", JournalMode: "wal"},
+			wantError: ErrSqliteInvalidJournalMode,
+		},
+		{
+			name:      "invalid config: busy timeout with negative value",
+			config:    Config{Path: "app.db", BusyTimeout: pint(-1)},
+			wantError: ErrSqliteBusyTimeoutInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := tt.config.Build()
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Errorf("error: got %v, want %v", err, tt.wantError)
+					return
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if ds != tt.wantDSN {
+				t.Errorf("dsn: got %s, want %s", ds, tt.wantDSN)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package sqlserver
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*Config)(nil)
+
+// Lint reports best-practice advisories for the configuration: disabled encryption,
+// an unverified server certificate, a missing connection timeout and a short password.
+func (c *Config) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	switch c.Encrypt {
+	case "disable", "false", "optional":
+		warnings = append(warnings, dsn.Warning{
+			Field:    "encrypt",
+			Severity: dsn.SeverityWarning,
+			Message:  "encrypt=" + c.Encrypt + " does not require TLS for the whole session",
+		})
+	}
+
+	if c.TrustServerCertificate != nil && *c.TrustServerCertificate {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "trustServerCertificate",
+			Severity: dsn.SeverityWarning,
+			Message:  "the server certificate is not verified",
+		})
+	}
+
+	if c.ConnectionTimeout == nil || *c.ConnectionTimeout == 0 {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "connectionTimeout",
+			Severity: dsn.SeverityInfo,
+			Message:  "no connection timeout set; connecting may block indefinitely",
+		})
+	}
+
+	return append(warnings, dsn.LintPassword(c.Password)...)
+}
@@ -0,0 +1,54 @@
+package sqlserver
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   map[string]dsn.Severity
+	}{
+		{
+			name: "hardened config",
+			config: Config{
+				Password:          "correct-horse-battery",
+				Encrypt:           "strict",
+				ConnectionTimeout: pint(30),
+			},
+			want: map[string]dsn.Severity{},
+		},
+		{
+			name: "encryption disabled, trusted certificate, no timeout, short password",
+			config: Config{
+				Password:               "secret",
+				Encrypt:                "disable",
+				TrustServerCertificate: pbool(true),
+			},
+			want: map[string]dsn.Severity{
+				"encrypt":                dsn.SeverityWarning,
+				"trustServerCertificate": dsn.SeverityWarning,
+				"connectionTimeout":      dsn.SeverityInfo,
+				"password":               dsn.SeverityWarning,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Lint(&tt.config).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package dsn_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn/mongo"
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/redis"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+)
+
+// TestString_MasksPassword verifies that no driver config leaks its password through String, %v or %+v.
+func TestString_MasksPassword(t *testing.T) {
+	const password = "s3cr3t-p4ss"
+
+	tests := []struct {
+		driver string
+		config fmt.Stringer
+	}{
+		{driver: "mysql", config: &mysql.Config{Host: "db", User: "app", Password: password}},
+		{driver: "postgres", config: &postgres.Config{Host: "db", User: "app", Password: password}},
+		{driver: "oracle", config: &oracle.StandaloneConfig{Host: "db", User: "app", Password: password}},
+		{driver: "sqlserver", config: &sqlserver.Config{Host: "db", User: "sa", Password: password}},
+		{driver: "mongo", config: &mongo.Config{Hosts: []string{"db"}, User: "app", Password: password}},
+		{driver: "redis", config: &redis.Config{Host: "cache", Password: password}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			for _, out := range []string{tt.config.String(), fmt.Sprintf("%v", tt.config), fmt.Sprintf("%+v", tt.config)} {
+				if strings.Contains(out, password) {
+					t.Errorf("output leaks password: %s", out)
+				}
+
+				if !strings.Contains(out, `Password: "****"`) {
+					t.Errorf("output %s does not mask the password", out)
+				}
+			}
+		})
+	}
+}
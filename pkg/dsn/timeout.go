@@ -0,0 +1,19 @@
+package dsn
+
+// ConnectTimeoutSetter is implemented by DSN configurations with a connection timeout, so a default can be
+// enforced regardless of the driver.
+type ConnectTimeoutSetter interface {
+	// SetConnectTimeout sets the connection timeout to seconds, unless one is already configured.
+	SetConnectTimeout(seconds int)
+}
+
+// ApplyConnectTimeout sets the connection timeout of d to seconds when d supports one and has none configured,
+// e.g. to enforce an organization-wide default before Build. It reports whether d supports a connection timeout.
+func ApplyConnectTimeout(d DSN, seconds int) bool {
+	s, ok := d.(ConnectTimeoutSetter)
+	if ok {
+		s.SetConnectTimeout(seconds)
+	}
+
+	return ok
+}
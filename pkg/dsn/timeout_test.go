@@ -0,0 +1,34 @@
+package dsn
+
+import "testing"
+
+type timeoutDSN struct {
+	countingDSN
+	timeout *int
+}
+
+func (d *timeoutDSN) SetConnectTimeout(seconds int) {
+	if d.timeout == nil {
+		d.timeout = &seconds
+	}
+}
+
+func TestApplyConnectTimeout(t *testing.T) {
+	d := &timeoutDSN{}
+	if !ApplyConnectTimeout(d, 10) {
+		t.Fatal("expected ApplyConnectTimeout to report support")
+	}
+
+	if d.timeout == nil || *d.timeout != 10 {
+		t.Errorf("timeout = %v, want 10", d.timeout)
+	}
+
+	ApplyConnectTimeout(d, 30)
+	if *d.timeout != 10 {
+		t.Errorf("timeout = %d, want the configured 10 to be kept", *d.timeout)
+	}
+
+	if ApplyConnectTimeout(&countingDSN{}, 10) {
+		t.Error("expected ApplyConnectTimeout to report no support")
+	}
+}
@@ -0,0 +1,126 @@
+// Package tunneled reads an SSH tunnel and a database DSN from one YAML document and connects to the database
+// through the tunnel. Like sqldb it imports no SQL driver: callers register the driver they need with a blank import.
+package tunneled
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/sqldb"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+	"github.com/pperesbr/gokit/pkg/tunnel"
+)
+
+var (
+	ErrTunneledSSHRequired       = errors.New("tunneled: ssh is required")
+	ErrTunneledUnsupportedDriver = errors.New("tunneled: unsupported driver, valid values are: mysql, oracle, postgres, sqlserver")
+)
+
+// Config is an SSH tunnel and the database reached through it:
+//
+//	ssh:
+//	  user: deploy
+//	  host: bastion.example.com
+//	  keyFile: ~/.ssh/id_ed25519
+//	driver: postgres
+//	database:
+//	  host: db.internal
+//	  user: app
+//	  password: secret
+//	  database: app
+//
+// The database block uses the yaml keys of the driver's Config; an oracle block is an oracle.StandaloneConfig.
+type Config struct {
+	SSH    *tunnel.SSHConfig
+	Driver string
+	DSN    dsn.Endpoint
+}
+
+// Load decodes a Config from data. The ssh block is loaded like tunnel.LoadSSHConfig; the database block is
+// decoded like dsn.Load and is not validated until it is built.
+func Load(data []byte) (*Config, error) {
+	var doc struct {
+		SSH      yaml.Node `yaml:"ssh"`
+		Driver   string    `yaml:"driver"`
+		Database yaml.Node `yaml:"database"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("tunneled: failed to parse config: %w", err)
+	}
+
+	if doc.SSH.IsZero() {
+		return nil, ErrTunneledSSHRequired
+	}
+
+	endpoint, err := newEndpoint(doc.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	sshData, err := yaml.Marshal(&doc.SSH)
+	if err != nil {
+		return nil, fmt.Errorf("tunneled: failed to parse ssh: %w", err)
+	}
+
+	sshConfig, err := tunnel.LoadSSHConfig(sshData)
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.Database.IsZero() {
+		if err := doc.Database.Decode(endpoint); err != nil {
+			return nil, fmt.Errorf("tunneled: failed to parse database: %w", err)
+		}
+	}
+
+	return &Config{SSH: sshConfig, Driver: doc.Driver, DSN: endpoint}, nil
+}
+
+// newEndpoint returns an empty configuration for driver.
+func newEndpoint(driver string) (dsn.Endpoint, error) {
+	switch driver {
+	case "mysql":
+		return &mysql.Config{}, nil
+	case "oracle":
+		return &oracle.StandaloneConfig{}, nil
+	case "postgres":
+		return &postgres.Config{}, nil
+	case "sqlserver":
+		return &sqlserver.Config{}, nil
+	default:
+		return nil, ErrTunneledUnsupportedDriver
+	}
+}
+
+// Connect loads a Config from data, starts a tunnel to the database host and port and opens the database through
+// the tunnel's local address with sqldb.Open. The returned *sql.DB is not pinged. The caller closes the *sql.DB and
+// then stops the tunnel.
+func Connect(data []byte) (*sql.DB, *tunnel.Tunnel, error) {
+	cfg, err := Load(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t, err := tunnel.ForDSN(cfg.SSH, cfg.DSN, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := t.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sqldb.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, nil, errors.Join(err, t.Stop())
+	}
+
+	return db, t, nil
+}
@@ -0,0 +1,318 @@
+package tunneled
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+	"github.com/pperesbr/gokit/pkg/dsn/mysql"
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+)
+
+const testSSH = `
+ssh:
+  user: testuser
+  password: testpass
+  host: 127.0.0.1
+  port: 2222
+  insecure: true
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		want   dsn.Endpoint
+	}{
+		{name: "mysql", driver: "mysql", want: &mysql.Config{}},
+		{name: "oracle", driver: "oracle", want: &oracle.StandaloneConfig{}},
+		{name: "postgres", driver: "postgres", want: &postgres.Config{}},
+		{name: "sqlserver", driver: "sqlserver", want: &sqlserver.Config{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testSSH + "driver: " + tt.driver + "\ndatabase:\n  host: db.internal\n  port: 4000\n"
+
+			cfg, err := Load([]byte(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Driver != tt.driver {
+				t.Errorf("Driver = %q, want %q", cfg.Driver, tt.driver)
+			}
+
+			if fmt.Sprintf("%T", cfg.DSN) != fmt.Sprintf("%T", tt.want) {
+				t.Errorf("DSN type = %T, want %T", cfg.DSN, tt.want)
+			}
+
+			if host, port := cfg.DSN.HostPort(); host != "db.internal" || port != 4000 {
+				t.Errorf("HostPort() = %s, %d, want db.internal, 4000", host, port)
+			}
+
+			if cfg.SSH.Addr() != "127.0.0.1:2222" {
+				t.Errorf("SSH.Addr() = %s, want 127.0.0.1:2222", cfg.SSH.Addr())
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr error
+	}{
+		{name: "missing ssh", yaml: "driver: postgres\n", wantErr: ErrTunneledSSHRequired},
+		{name: "missing driver", yaml: testSSH, wantErr: ErrTunneledUnsupportedDriver},
+		{name: "unsupported driver", yaml: testSSH + "driver: redis\n", wantErr: ErrTunneledUnsupportedDriver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load([]byte(tt.yaml)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// greetingDriver is a database/sql driver that dials the host and port of a postgres URL and reads a greeting.
+type greetingDriver struct {
+	dialed    chan string
+	greetings chan string
+}
+
+func (d *greetingDriver) Open(name string) (driver.Conn, error) {
+	u, err := url.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d.dialed <- u.Host
+
+	conn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	greeting, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	d.greetings <- string(greeting)
+	return greetingConn{}, nil
+}
+
+type greetingConn struct{}
+
+func (greetingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("stub: not supported")
+}
+func (greetingConn) Close() error              { return nil }
+func (greetingConn) Begin() (driver.Tx, error) { return nil, errors.New("stub: not supported") }
+
+// startSSHServer starts an SSH server accepting testuser/testpass that serves direct-tcpip channels.
+func startSSHServer(t *testing.T) net.Listener {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "testuser" && string(pass) == "testpass" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+
+	return listener
+}
+
+// serveSSH forwards every direct-tcpip channel of conn to its destination.
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		var payload struct {
+			DestHost   string
+			DestPort   uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		ssh.Unmarshal(newChannel.ExtraData(), &payload)
+
+		dest, err := net.Dial("tcp", net.JoinHostPort(payload.DestHost, strconv.Itoa(int(payload.DestPort))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			dest.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+
+		go func() {
+			defer channel.Close()
+			defer dest.Close()
+			io.Copy(channel, dest)
+		}()
+		go func() {
+			defer channel.Close()
+			defer dest.Close()
+			io.Copy(dest, channel)
+		}()
+	}
+}
+
+// TestConnect opens a database through an SSH tunnel to a server that writes a greeting and checks the greeting
+// reaches the driver through the tunnel's local address.
+func TestConnect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping tunneled connection test in short mode")
+	}
+
+	sshServer := startSSHServer(t)
+
+	dbServer, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	t.Cleanup(func() { dbServer.Close() })
+
+	go func() {
+		for {
+			conn, err := dbServer.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("hello from postgres"))
+			conn.Close()
+		}
+	}()
+
+	stub := &greetingDriver{dialed: make(chan string, 1), greetings: make(chan string, 1)}
+	sql.Register("greeting-tunneled", stub)
+	dsn.SetSQLDriverName("postgres", "greeting-tunneled")
+	t.Cleanup(func() { dsn.SetSQLDriverName("postgres", "") })
+
+	data := fmt.Sprintf(`
+ssh:
+  user: testuser
+  password: testpass
+  host: 127.0.0.1
+  port: %d
+  insecure: true
+driver: postgres
+database:
+  host: 127.0.0.1
+  port: %d
+  user: app
+  password: secret
+  database: app
+`, sshServer.Addr().(*net.TCPAddr).Port, dbServer.Addr().(*net.TCPAddr).Port)
+
+	db, tun, err := Connect([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Stop()
+	defer db.Close()
+
+	if tun.RemoteAddr() != dbServer.Addr().String() {
+		t.Errorf("tunnel remote addr = %s, want %s", tun.RemoteAddr(), dbServer.Addr().String())
+	}
+
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	if got := <-stub.dialed; got != tun.LocalAddr() {
+		t.Errorf("dialed %s, want the tunnel local addr %s", got, tun.LocalAddr())
+	}
+
+	if got := <-stub.greetings; got != "hello from postgres" {
+		t.Errorf("greeting = %q, want %q", got, "hello from postgres")
+	}
+}
+
+func TestConnect_SSHUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	data := fmt.Sprintf("ssh:\n  user: testuser\n  password: testpass\n  host: 127.0.0.1\n  port: %d\n  insecure: true\n"+
+		"driver: postgres\ndatabase:\n  host: db.internal\n", port)
+
+	db, tun, err := Connect([]byte(data))
+	if err == nil {
+		db.Close()
+		tun.Stop()
+		t.Fatal("expected error for unreachable ssh server")
+	}
+
+	if db != nil || tun != nil {
+		t.Error("expected nil *sql.DB and *tunnel.Tunnel on error")
+	}
+}
@@ -0,0 +1,71 @@
+package tunnel
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// BreakerState describes whether a Manager keeps retrying a failed tunnel at its base delay or has backed off.
+type BreakerState string
+
+const (
+	// BreakerClosed is the state of a tunnel that is healthy or has failed fewer times in a row than the threshold.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen is the state of a tunnel that has failed at least the threshold times in a row; it is retried with
+	// a jittered exponential delay.
+	BreakerOpen BreakerState = "open"
+)
+
+// Breaker reports the circuit breaker of a tunnel registered in a Manager.
+type Breaker struct {
+	State    BreakerState
+	Failures int
+	RetryAt  time.Time
+}
+
+// defaultBreakerCheckInterval is how often the Manager looks for failed tunnels to restart.
+const defaultBreakerCheckInterval = 100 * time.Millisecond
+
+// breakerPolicy holds the circuit breaker settings of a Manager.
+type breakerPolicy struct {
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// delay returns the un-jittered wait before the next restart after failures consecutive failures: the base delay
+// while the breaker is closed, then doubling for every failure past the threshold up to the maximum delay.
+func (p breakerPolicy) delay(failures int) time.Duration {
+	if failures < p.threshold {
+		return p.baseDelay
+	}
+
+	d := p.baseDelay
+	for range failures - p.threshold + 1 {
+		d *= 2
+		if d >= p.maxDelay {
+			return p.maxDelay
+		}
+	}
+
+	return d
+}
+
+// state returns the breaker state after failures consecutive failures.
+func (p breakerPolicy) state(failures int) BreakerState {
+	if failures >= p.threshold {
+		return BreakerOpen
+	}
+	return BreakerClosed
+}
+
+// jitter returns a random delay in [d-spread, d+spread], or d when spread is not positive, so tunnels sharing a
+// bastion or an SSH server do not retry in lockstep. It is used by both the Manager's breaker and reconnection.
+func jitter(d, spread time.Duration) time.Duration {
+	if spread <= 0 {
+		return d
+	}
+
+	return d - spread + rand.N(2*spread+1)
+}
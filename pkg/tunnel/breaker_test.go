@@ -0,0 +1,93 @@
+package tunnel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerPolicy_Delay(t *testing.T) {
+	p := breakerPolicy{threshold: 3, baseDelay: time.Second, maxDelay: 10 * time.Second}
+
+	tests := []struct {
+		failures  int
+		wantDelay time.Duration
+		wantState BreakerState
+	}{
+		{failures: 1, wantDelay: time.Second, wantState: BreakerClosed},
+		{failures: 2, wantDelay: time.Second, wantState: BreakerClosed},
+		{failures: 3, wantDelay: 2 * time.Second, wantState: BreakerOpen},
+		{failures: 4, wantDelay: 4 * time.Second, wantState: BreakerOpen},
+		{failures: 5, wantDelay: 8 * time.Second, wantState: BreakerOpen},
+		{failures: 6, wantDelay: 10 * time.Second, wantState: BreakerOpen},
+		{failures: 100, wantDelay: 10 * time.Second, wantState: BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		if got := p.delay(tt.failures); got != tt.wantDelay {
+			t.Errorf("delay(%d) = %s, want %s", tt.failures, got, tt.wantDelay)
+		}
+		if got := p.state(tt.failures); got != tt.wantState {
+			t.Errorf("state(%d) = %s, want %s", tt.failures, got, tt.wantState)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 2 * time.Second
+
+	tests := []struct {
+		name    string
+		jitter  func(time.Duration) time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "spread",
+			jitter:  func(d time.Duration) time.Duration { return jitter(d, d/2) },
+			wantMin: time.Second,
+			wantMax: 3 * time.Second,
+		},
+		{
+			name:    "no spread",
+			jitter:  func(d time.Duration) time.Duration { return jitter(d, 0) },
+			wantMin: d,
+			wantMax: d,
+		},
+		{
+			name:    "manager default",
+			jitter:  NewManager().jitter,
+			wantMin: time.Second,
+			wantMax: d,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 1000 {
+				if got := tt.jitter(d); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("jitter(%s) = %s, want within [%s, %s]", d, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestTunnel_SetReconnectJitter(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		want     float64
+	}{
+		{fraction: 0.25, want: 0.25},
+		{fraction: -1, want: 0},
+		{fraction: 2, want: 1},
+	}
+
+	for _, tt := range tests {
+		tun := &Tunnel{}
+		tun.SetReconnectJitter(tt.fraction)
+
+		if tun.reconnectJitter != tt.want {
+			t.Errorf("SetReconnectJitter(%v): got %v, want %v", tt.fraction, tun.reconnectJitter, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// NewChainedTunnel initializes a Tunnel that reaches remoteHost:remotePort through an ordered list of SSH hops, like
+// ssh -J: each hop is dialed through the previous one and the forward goes through the last hop. UpdateConfig replaces
+// the last hop.
+func NewChainedTunnel(hops []*SSHConfig, remoteHost string, remotePort, localPort int) *Tunnel {
+	var config *SSHConfig
+	var jumps []*SSHConfig
+	if len(hops) > 0 {
+		config = hops[len(hops)-1]
+		jumps = append(jumps, hops[:len(hops)-1]...)
+	}
+
+	t := NewTunnel(config, remoteHost, remotePort, localPort)
+	t.jumps = jumps
+	t.chained = true
+	return t
+}
+
+// dial connects to the tunnel's SSH server, going through its jump hosts if any.
+func (t *Tunnel) dial(ctx context.Context) (*ssh.Client, error) {
+	t.mu.RLock()
+	config := t.config
+	jumps := t.jumps
+	t.mu.RUnlock()
+
+	return dialSSHChain(ctx, jumps, config)
+}
+
+// dialSSHChain dials each of jumps through the previous one and then config through the last of them. Closing the
+// returned client, or losing any hop, closes the whole chain.
+func dialSSHChain(ctx context.Context, jumps []*SSHConfig, config *SSHConfig) (*ssh.Client, error) {
+	if len(jumps) == 0 {
+		return dialSSH(ctx, config)
+	}
+
+	hops := append(append([]*SSHConfig(nil), jumps...), config)
+	clients := make([]*ssh.Client, 0, len(hops))
+	closeAll := func() {
+		for i := len(clients) - 1; i >= 0; i-- {
+			_ = clients[i].Close()
+		}
+	}
+
+	client, err := dialSSH(ctx, hops[0])
+	if err != nil {
+		return nil, fmt.Errorf("hop 0 (%s): %w", hops[0].Addr(), err)
+	}
+	clients = append(clients, client)
+
+	for i, hop := range hops[1:] {
+		conn, err := client.DialContext(ctx, "tcp", hop.Addr())
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("hop %d (%s): %w", i+1, hop.Addr(), err)
+		}
+
+		client, err = handshakeSSH(ctx, conn, hop)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("hop %d (%s): %w", i+1, hop.Addr(), err)
+		}
+		clients = append(clients, client)
+	}
+
+	// The final client only owns a channel of the previous hop, so the outer hops are closed once it is done.
+	go func() {
+		_ = client.Wait()
+		closeAll()
+	}()
+
+	return client, nil
+}
@@ -0,0 +1,93 @@
+package tunnel
+
+import (
+	"net"
+	"testing"
+)
+
+// TestNewChainedTunnel_NoHops verifies that a chained tunnel without hops fails validation.
+func TestNewChainedTunnel_NoHops(t *testing.T) {
+	tun := NewChainedTunnel(nil, "remote-host", 1521, 0)
+
+	if err := tun.Validate(); err == nil {
+		t.Fatal("expected error for chained tunnel without hops")
+	}
+}
+
+// TestNewChainedTunnel_NilHop verifies that a nil hop in the chain fails validation.
+func TestNewChainedTunnel_NilHop(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	tun := NewChainedTunnel([]*SSHConfig{nil, cfg}, "remote-host", 1521, 0)
+
+	if err := tun.Validate(); err == nil {
+		t.Fatal("expected error for nil hop")
+	}
+}
+
+// TestChainedTunnel_ForwardData verifies forwarding through two stacked SSH servers, where the inner one is dialed
+// through the outer one.
+func TestChainedTunnel_ForwardData(t *testing.T) {
+	jumpServer, jumpCfg := setupTestSSHServer(t)
+	defer jumpServer.Close()
+
+	bastionServer, bastionCfg := setupTestSSHServer(t)
+	defer bastionServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from oracle")
+	defer destServer.Close()
+
+	hops := []*SSHConfig{jumpCfg, bastionCfg}
+	tun := NewChainedTunnel(hops, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if got := readFromTunnel(t, tun.LocalAddr()); got != "hello from oracle" {
+		t.Errorf("expected 'hello from oracle', got '%s'", got)
+	}
+}
+
+// TestChainedTunnel_InnerHopFails verifies that a failing inner hop aborts Start and closes the outer hops.
+func TestChainedTunnel_InnerHopFails(t *testing.T) {
+	jumpServer, jumpCfg := setupTestSSHServer(t)
+	defer jumpServer.Close()
+
+	bastionServer, bastionCfg := setupTestSSHServer(t)
+	defer bastionServer.Close()
+
+	badCfg, err := NewInsecureSSHConfig("testuser", "wrongpass", "", "127.0.0.1", bastionCfg.Port)
+	if err != nil {
+		t.Fatalf("failed to create ssh config: %v", err)
+	}
+
+	tun := NewChainedTunnel([]*SSHConfig{jumpCfg, badCfg}, "127.0.0.1", 1521, 0)
+
+	if err := tun.Start(); err == nil {
+		tun.Close()
+		t.Fatal("expected error when an inner hop fails to authenticate")
+	}
+
+	if tun.Status() != StatusError {
+		t.Errorf("expected status %s, got %s", StatusError, tun.Status())
+	}
+}
+
+// TestChainedTunnel_OuterHopLost verifies that losing the first hop puts the tunnel in error.
+func TestChainedTunnel_OuterHopLost(t *testing.T) {
+	jumpServer, jumpCfg := setupTestSSHServer(t)
+
+	bastionServer, bastionCfg := setupTestSSHServer(t)
+	defer bastionServer.Close()
+
+	tun := NewChainedTunnel([]*SSHConfig{jumpCfg, bastionCfg}, "127.0.0.1", 1521, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	jumpServer.Close()
+	waitForStatus(t, tun, StatusError)
+}
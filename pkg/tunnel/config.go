@@ -1,23 +1,68 @@
 package tunnel
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/yaml.v3"
 )
 
+// defaultDialTimeout is the DialTimeout set by Validate when none is configured.
+const defaultDialTimeout = 10 * time.Second
+
+// sshAuthSockEnv is the environment variable holding the path of the running ssh-agent socket.
+const sshAuthSockEnv = "SSH_AUTH_SOCK"
+
+var (
+	// ErrKeyPassphraseRequired is returned when the keyFile is encrypted and no keyPassphrase was provided.
+	ErrKeyPassphraseRequired = errors.New("keyFile is encrypted, keyPassphrase is required")
+
+	// ErrKeyPassphraseIncorrect is returned when the keyPassphrase does not decrypt the keyFile.
+	ErrKeyPassphraseIncorrect = errors.New("keyPassphrase is incorrect for keyFile")
+
+	// ErrHostKeyMismatch is returned during the SSH handshake when the server host key does not match the pinned
+	// hostKeyFingerprint.
+	ErrHostKeyMismatch = errors.New("host key does not match hostKeyFingerprint")
+
+	// ErrHostKeyVerificationRequired is returned by Validate when no knownHostsFile or hostKeyFingerprint is set,
+	// ~/.ssh/known_hosts does not exist and insecure was not requested.
+	ErrHostKeyVerificationRequired = errors.New("knownHostsFile, hostKeyFingerprint or insecure is required when ~/.ssh/known_hosts does not exist")
+)
+
+// fingerprintPrefix is the prefix of the fingerprints returned by ssh.FingerprintSHA256.
+const fingerprintPrefix = "SHA256:"
+
 // SSHConfig represents the configuration for establishing an SSH connection, including authentication and host details.
 type SSHConfig struct {
-	User            string              `yaml:"user"`
-	Password        string              `yaml:"password"`
-	KeyFile         string              `yaml:"keyFile"`
-	Host            string              `yaml:"host"`
-	KnownHostsFile  string              `yaml:"knownHostsFile"`
-	Port            int                 `yaml:"port"`
-	AuthMethods     []ssh.AuthMethod    `yaml:"-"` // <- mudou
-	HostKeyCallback ssh.HostKeyCallback `yaml:"-"`
+	User               string              `yaml:"user"`
+	Password           string              `yaml:"password"`
+	KeyFile            string              `yaml:"keyFile"`
+	KeyPassphrase      string              `yaml:"keyPassphrase"`
+	Host               string              `yaml:"host"`
+	KnownHostsFile     string              `yaml:"knownHostsFile"`
+	HostKeyFingerprint string              `yaml:"hostKeyFingerprint"`
+	Port               int                 `yaml:"port"`
+	UseAgent           bool                `yaml:"useAgent"`
+	Insecure           bool                `yaml:"insecure"`
+	Ciphers            []string            `yaml:"ciphers"`
+	MACs               []string            `yaml:"macs"`
+	KeyExchanges       []string            `yaml:"keyExchanges"`
+	DialTimeout        time.Duration       `yaml:"dialTimeout"`
+	AuthMethods        []ssh.AuthMethod    `yaml:"-"` // <- mudou
+	HostKeyCallback    ssh.HostKeyCallback `yaml:"-"`
+
+	keySigners    []ssh.Signer
+	verifyHostKey ssh.HostKeyCallback
 }
 
 // NewSSHConfig creates and returns a new SSHConfig object with the specified parameters and performs required validations.
@@ -39,22 +84,154 @@ func NewSSHConfig(user, password, keyFile, host, knownHostsFile string, port int
 	return cfg, nil
 }
 
+// NewInsecureSSHConfig creates and returns a new SSHConfig that skips host key verification. It is meant for
+// development and testing only.
+func NewInsecureSSHConfig(user, password, keyFile, host string, port int) (*SSHConfig, error) {
+	cfg := &SSHConfig{
+		User:     user,
+		Password: password,
+		KeyFile:  keyFile,
+		Host:     host,
+		Port:     port,
+		Insecure: true,
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// NewSSHConfigWithAgent creates and returns a new SSHConfig that authenticates using the keys held by the running
+// ssh-agent, located through the SSH_AUTH_SOCK environment variable.
+func NewSSHConfigWithAgent(user, host, knownHostsFile string, port int) (*SSHConfig, error) {
+	cfg := &SSHConfig{
+		User:           user,
+		Host:           host,
+		KnownHostsFile: knownHostsFile,
+		Port:           port,
+		UseAgent:       true,
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// NewSSHConfigWithFingerprint creates and returns a new SSHConfig that verifies the server host key against the pinned
+// SHA256 fingerprint (as printed by ssh-keygen -lf) instead of a known_hosts file.
+func NewSSHConfigWithFingerprint(user, password, keyFile, host, hostKeyFingerprint string, port int) (*SSHConfig, error) {
+	cfg := &SSHConfig{
+		User:               user,
+		Password:           password,
+		KeyFile:            keyFile,
+		Host:               host,
+		HostKeyFingerprint: hostKeyFingerprint,
+		Port:               port,
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadSSHConfig unmarshals an SSHConfig from a YAML document using the fields' yaml tags, expands a leading "~" in
+// keyFile and knownHostsFile to the user's home directory and runs Validate to load the key file and prepare the
+// authentication methods and host key callback.
+func LoadSSHConfig(data []byte) (*SSHConfig, error) {
+	cfg := &SSHConfig{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse ssh config: %w", err)
+	}
+
+	var err error
+	if cfg.KeyFile, err = expandHome(cfg.KeyFile); err != nil {
+		return nil, err
+	}
+
+	if cfg.KnownHostsFile, err = expandHome(cfg.KnownHostsFile); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// expandHome replaces a leading "~" in path with the current user's home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
 // Addr returns the SSH host and port formatted as a string in the "host:port" format.
 func (c *SSHConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
-// IsInsecure determines if the SSHConfig lacks a KnownHostsFile, implying an insecure host key verification strategy.
+// IsInsecure determines if the SSHConfig lacks a KnownHostsFile, a HostKeyFingerprint and a callback set with
+// WithHostKeyCallback, implying an insecure host key verification strategy. After Validate, KnownHostsFile is set to
+// ~/.ssh/known_hosts when that file was picked as the default.
 func (c *SSHConfig) IsInsecure() bool {
-	return c.KnownHostsFile == ""
+	return c.KnownHostsFile == "" && c.HostKeyFingerprint == "" && c.verifyHostKey == nil
+}
+
+// WithHostKeyCallback verifies the server host key with cb, e.g. against a custom CA or a remote API. It takes
+// precedence over KnownHostsFile, HostKeyFingerprint and Insecure, and is kept by later calls to Validate.
+func (c *SSHConfig) WithHostKeyCallback(cb ssh.HostKeyCallback) *SSHConfig {
+	c.verifyHostKey = cb
+	if cb != nil {
+		c.HostKeyCallback = cb
+	}
+	return c
+}
+
+// defaultKnownHostsFile returns the path of ~/.ssh/known_hosts, or "" when it cannot be resolved or does not exist.
+func defaultKnownHostsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	path := filepath.Join(home, ".ssh", "known_hosts")
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+
+	return path
 }
 
 // Validate checks the SSHConfig fields for required values, sets defaults, and prepares authentication methods.
+// A callback set with WithHostKeyCallback always verifies host keys. Otherwise, without a KnownHostsFile or
+// HostKeyFingerprint, host keys are verified against ~/.ssh/known_hosts when it exists; verification is only skipped
+// when Insecure is set.
 func (c *SSHConfig) Validate() error {
 	if c.Port == 0 {
 		c.Port = 22
 	}
 
+	if c.DialTimeout == 0 {
+		c.DialTimeout = defaultDialTimeout
+	}
+
 	if c.Host == "" {
 		return fmt.Errorf("host is required")
 	}
@@ -63,44 +240,187 @@ func (c *SSHConfig) Validate() error {
 		return fmt.Errorf("user is required")
 	}
 
-	if c.Password == "" && c.KeyFile == "" {
+	if c.Password == "" && c.KeyFile == "" && !c.UseAgent {
 		return fmt.Errorf("password or keyFile is required")
 	}
 
+	var signers []ssh.Signer
 	if c.KeyFile != "" {
 		key, err := os.ReadFile(c.KeyFile)
 		if err != nil {
 			return fmt.Errorf("failed to read keyFile: %w", err)
 		}
 
-		signer, err := ssh.ParsePrivateKey(key)
+		signer, err := parsePrivateKey(key, c.KeyPassphrase)
 		if err != nil {
-			return fmt.Errorf("failed to parse keyFile: %w", err)
+			return err
 		}
 
-		c.AuthMethods = []ssh.AuthMethod{ssh.PublicKeys(signer)}
-	} else {
-		c.AuthMethods = []ssh.AuthMethod{
-			ssh.Password(c.Password),
-			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
-				answers := make([]string, len(questions))
-				for i := range questions {
-					answers[i] = c.Password
-				}
-				return answers, nil
-			}),
+		signers = append(signers, signer)
+	}
+
+	if c.UseAgent {
+		// The agent is connected for each handshake; this only checks that it is reachable.
+		conn, err := dialAgent()
+		if err != nil {
+			return err
 		}
+		_ = conn.Close()
 	}
 
+	c.keySigners = signers
+	c.AuthMethods = c.authMethods(nil)
+
+	if c.verifyHostKey != nil {
+		c.HostKeyCallback = c.verifyHostKey
+		return nil
+	}
+
+	if c.KnownHostsFile == "" && c.HostKeyFingerprint == "" && !c.Insecure {
+		c.KnownHostsFile = defaultKnownHostsFile()
+		if c.KnownHostsFile == "" {
+			return ErrHostKeyVerificationRequired
+		}
+	}
+
+	var knownHostsCallback ssh.HostKeyCallback
 	if c.KnownHostsFile != "" {
 		hostKeyCallback, err := knownhosts.New(c.KnownHostsFile)
 		if err != nil {
 			return fmt.Errorf("failed to load known_hosts: %w", err)
 		}
-		c.HostKeyCallback = hostKeyCallback
-	} else {
+		knownHostsCallback = hostKeyCallback
+	}
+
+	switch {
+	case c.HostKeyFingerprint != "":
+		fingerprint, err := normalizeFingerprint(c.HostKeyFingerprint)
+		if err != nil {
+			return err
+		}
+		c.HostKeyCallback = pinnedHostKeyCallback(fingerprint, knownHostsCallback)
+	case knownHostsCallback != nil:
+		c.HostKeyCallback = knownHostsCallback
+	default:
 		c.HostKeyCallback = ssh.InsecureIgnoreHostKey()
 	}
 
 	return nil
 }
+
+// normalizeFingerprint validates a SHA256 host key fingerprint, adding the "SHA256:" prefix when it is missing.
+func normalizeFingerprint(fingerprint string) (string, error) {
+	fingerprint = strings.TrimSpace(fingerprint)
+	if !strings.HasPrefix(fingerprint, fingerprintPrefix) {
+		if strings.Contains(fingerprint, ":") {
+			return "", fmt.Errorf("hostKeyFingerprint must be a SHA256 fingerprint")
+		}
+		fingerprint = fingerprintPrefix + fingerprint
+	}
+
+	if _, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(fingerprint, fingerprintPrefix)); err != nil {
+		return "", fmt.Errorf("hostKeyFingerprint is not a valid SHA256 fingerprint: %w", err)
+	}
+
+	return fingerprint, nil
+}
+
+// pinnedHostKeyCallback accepts only host keys whose SHA256 fingerprint is fingerprint and, when knownHosts is set,
+// that are also accepted by it.
+func pinnedHostKeyCallback(fingerprint string, knownHosts ssh.HostKeyCallback) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if ssh.FingerprintSHA256(key) != fingerprint {
+			return fmt.Errorf("%w: got %s", ErrHostKeyMismatch, ssh.FingerprintSHA256(key))
+		}
+
+		if knownHosts != nil {
+			return knownHosts(hostname, remote, key)
+		}
+
+		return nil
+	}
+}
+
+// authMethods returns the authentication methods for the key file signers and password prepared by Validate.
+// With UseAgent, the keys of agentClient are offered before the key file; a nil agentClient offers the key file only.
+func (c *SSHConfig) authMethods(agentClient agent.ExtendedAgent) []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+	signers := c.keySigners
+
+	if c.UseAgent {
+		// Agent keys and the key file are offered through a single publickey method, since the ssh client
+		// skips any further method of a type it has already tried.
+		methods = append(methods, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			if agentClient == nil {
+				return signers, nil
+			}
+
+			agentSigners, err := agentClient.Signers()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list ssh-agent keys: %w", err)
+			}
+			return append(agentSigners, signers...), nil
+		}))
+	} else if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if c.Password != "" && c.KeyFile == "" {
+		password := c.Password
+		methods = append(methods,
+			ssh.Password(password),
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range questions {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	return methods
+}
+
+// dialAgent opens a connection to the ssh-agent referenced by SSH_AUTH_SOCK. The caller closes it.
+func dialAgent() (net.Conn, error) {
+	socket := os.Getenv(sshAuthSockEnv)
+	if socket == "" {
+		return nil, fmt.Errorf("%s is not set, ssh-agent is not available", sshAuthSockEnv)
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+
+	return conn, nil
+}
+
+// parsePrivateKey parses a PEM encoded private key, decrypting it with passphrase when the key is encrypted.
+func parsePrivateKey(key []byte, passphrase string) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(key)
+	if err == nil {
+		return signer, nil
+	}
+
+	var missingErr *ssh.PassphraseMissingError
+	if !errors.As(err, &missingErr) {
+		return nil, fmt.Errorf("failed to parse keyFile: %w", err)
+	}
+
+	if passphrase == "" {
+		return nil, ErrKeyPassphraseRequired
+	}
+
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	if errors.Is(err, x509.IncorrectPasswordError) {
+		return nil, ErrKeyPassphraseIncorrect
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keyFile: %w", err)
+	}
+
+	return signer, nil
+}
@@ -0,0 +1,67 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// ErrConnIdleTimeout is reported in the ConnEvent of a connection closed because no data moved for the connection
+// idle timeout.
+var ErrConnIdleTimeout = errors.New("connection closed after idle timeout")
+
+// SetConnIdleTimeout closes each forwarded connection once no data has moved in either direction for timeout and
+// counts it in Stats.TimedOutConnections. Unlike SetIdleTimeout it applies to single connections and leaves the
+// tunnel running. A timeout of 0 or less disables it, which is the default. It applies to connections accepted
+// afterwards.
+func (t *Tunnel) SetConnIdleTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connIdleTimeout = timeout
+}
+
+// activityReader records in last the time of every read that returned data.
+type activityReader struct {
+	r    io.Reader
+	now  func() time.Time
+	last *atomic.Int64
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if n > 0 {
+		a.last.Store(a.now().UnixNano())
+	}
+	return n, err
+}
+
+// watchConnIdle tears down local and remote once last is older than timeout, setting timedOut, until done is closed.
+func (t *Tunnel) watchConnIdle(local, remote net.Conn, last *atomic.Int64, timeout time.Duration, done chan struct{},
+	timedOut *atomic.Bool) {
+	ticker := time.NewTicker(max(timeout/4, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if t.now().Sub(time.Unix(0, last.Load())) < timeout {
+			continue
+		}
+
+		timedOut.Store(true)
+
+		// Deadlines unblock pending reads on connections that support them; closing covers the rest.
+		deadline := time.Now()
+		_ = local.SetDeadline(deadline)
+		_ = remote.SetDeadline(deadline)
+		_ = local.Close()
+		_ = remote.Close()
+		return
+	}
+}
@@ -0,0 +1,110 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestSetConnIdleTimeout_ClosesSilentConnection verifies that a connection that never transfers data is closed after
+// the connection idle timeout while the tunnel keeps running.
+func TestSetConnIdleTimeout_ClosesSilentConnection(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+	defer close(release)
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetConnIdleTimeout(100 * time.Millisecond)
+
+	closed := make(chan ConnEvent, 1)
+	tun.OnConnection(func(ev ConnEvent) {
+		if ev.Type == ConnClosed {
+			closed <- ev
+		}
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("expected the tunnel to close the connection, got %v", err)
+	}
+
+	select {
+	case ev := <-closed:
+		if !errors.Is(ev.Err, ErrConnIdleTimeout) {
+			t.Errorf("expected ErrConnIdleTimeout, got %v", ev.Err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for closed event")
+	}
+
+	stats := tun.Stats()
+	if stats.TimedOutConnections != 1 {
+		t.Errorf("expected 1 timed out connection, got %d", stats.TimedOutConnections)
+	}
+
+	if tun.Status() != StatusRunning {
+		t.Errorf("expected status %s, got %s", StatusRunning, tun.Status())
+	}
+}
+
+// TestSetConnIdleTimeout_ActiveConnection verifies that a connection moving data is not timed out.
+func TestSetConnIdleTimeout_ActiveConnection(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServerFunc(t, func(conn net.Conn) {
+		defer conn.Close()
+		for range 6 {
+			if _, err := conn.Write([]byte("tick")); err != nil {
+				return
+			}
+			time.Sleep(40 * time.Millisecond)
+		}
+	})
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetConnIdleTimeout(100 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(got) != "ticktickticktickticktick" {
+		t.Errorf("expected 6 ticks, got %q", got)
+	}
+
+	if got := tun.Stats().TimedOutConnections; got != 0 {
+		t.Errorf("expected 0 timed out connections, got %d", got)
+	}
+}
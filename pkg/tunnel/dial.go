@@ -0,0 +1,46 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+)
+
+// ErrDialUnsupported is returned by Dial for reverse and dynamic tunnels, which have no single remote address.
+var ErrDialUnsupported = errors.New("dial is only supported by local tunnels")
+
+// Dial opens a connection to the remote address through the SSH connection, bypassing the local listener. It
+// returns ErrNotRunning when the tunnel is not running. Connections opened with Dial are not counted in Stats and are
+// not subject to the connection limit or idle timeouts.
+func (t *Tunnel) Dial() (net.Conn, error) {
+	return t.DialContext(context.Background())
+}
+
+// DialContext is like Dial but gives up when ctx is done before the connection is established.
+func (t *Tunnel) DialContext(ctx context.Context) (net.Conn, error) {
+	if t.direction != DirectionLocal {
+		return nil, ErrDialUnsupported
+	}
+
+	t.mu.RLock()
+	status := t.status
+	client := t.client
+	remoteAddr := net.JoinHostPort(t.remoteHost, strconv.Itoa(t.remotePort))
+	t.mu.RUnlock()
+
+	if status != StatusRunning || client == nil {
+		return nil, ErrNotRunning
+	}
+
+	return client.DialContext(ctx, "tcp", remoteAddr)
+}
+
+// DialContextFunc returns a dial function with the signature database drivers accept for custom dialers. It ignores
+// the requested network and address, which drivers derive from the DSN and may have resolved to an IP, and dials the
+// tunnel's remote address with DialContext, so a database connection needs no local listener.
+func (t *Tunnel) DialContextFunc() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return t.DialContext(ctx)
+	}
+}
@@ -0,0 +1,137 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDial_ReachesDestination(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello through ssh")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, err := tun.Dial()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	got, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(got) != "hello through ssh" {
+		t.Errorf("expected 'hello through ssh', got '%s'", got)
+	}
+
+	if stats := tun.Stats(); stats.Connections != 0 {
+		t.Errorf("expected dialed connection to bypass stats, got %d connections", stats.Connections)
+	}
+}
+
+func TestDialContext_Canceled(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "unused")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := tun.DialContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDial_NotRunning(t *testing.T) {
+	cfg := &SSHConfig{User: "user", Password: "password", Host: "127.0.0.1", Port: 22, Insecure: true}
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+
+	if _, err := tun.Dial(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestDial_ReverseUnsupported(t *testing.T) {
+	cfg := &SSHConfig{User: "user", Password: "password", Host: "127.0.0.1", Port: 22, Insecure: true}
+	tun := NewReverseTunnel(cfg, "127.0.0.1", 8080, 3000)
+
+	if _, err := tun.Dial(); !errors.Is(err, ErrDialUnsupported) {
+		t.Errorf("expected ErrDialUnsupported, got %v", err)
+	}
+}
+
+// fakeDriver mimics a database driver that connects through a configurable dial function, like pgx's DialFunc or
+// go-ora's Dialer.
+type fakeDriver struct {
+	DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func (d *fakeDriver) greeting(ctx context.Context, addr string) (string, error) {
+	conn, err := d.DialFunc(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	data, err := io.ReadAll(conn)
+	return string(data), err
+}
+
+func TestDialContextFunc_DriverDialer(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from postgres")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	driver := &fakeDriver{DialFunc: tun.DialContextFunc()}
+
+	// The address comes from the DSN and is not reachable directly; the tunnel ignores it.
+	got, err := driver.greeting(context.Background(), "db.internal:5432")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != "hello from postgres" {
+		t.Errorf("expected 'hello from postgres', got '%s'", got)
+	}
+}
+
+func TestDialContextFunc_NotRunning(t *testing.T) {
+	cfg := &SSHConfig{User: "user", Password: "password", Host: "127.0.0.1", Port: 22, Insecure: true}
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+
+	driver := &fakeDriver{DialFunc: tun.DialContextFunc()}
+	if _, err := driver.greeting(context.Background(), "db.internal:5432"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
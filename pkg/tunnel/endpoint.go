@@ -0,0 +1,43 @@
+package tunnel
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+// ForDSN initializes a Tunnel forwarding localPort to the host and port of endpoint, so they are not duplicated between
+// the DSN and the tunnel configuration. Each time the tunnel starts, endpoint is redirected to the tunnel's local
+// address, so a DSN built afterwards connects through the tunnel. A localPort of 0 picks a free port on Start.
+func ForDSN(config *SSHConfig, endpoint dsn.Endpoint, localPort int) (*Tunnel, error) {
+	if endpoint == nil {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+
+	remoteHost, remotePort := endpoint.HostPort()
+
+	t := NewTunnel(config, remoteHost, remotePort, localPort)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	t.onStarted = func() {
+		endpoint.SetHostPort(t.dialHost(), t.LocalPort())
+	}
+
+	return t, nil
+}
+
+// dialHost returns the host clients should dial to reach the local listener: the bind address, or the loopback
+// address when bound to every interface.
+func (t *Tunnel) dialHost() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if ip := net.ParseIP(t.bindAddress); ip != nil && ip.IsUnspecified() {
+		return defaultBindAddress
+	}
+
+	return t.bindAddress
+}
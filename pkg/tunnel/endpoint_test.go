@@ -0,0 +1,84 @@
+package tunnel
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn/oracle"
+	"github.com/pperesbr/gokit/pkg/dsn/postgres"
+	"github.com/pperesbr/gokit/pkg/dsn/sqlserver"
+)
+
+// TestForDSN_RedirectsEndpoint verifies that the tunnel targets the DSN host and port and redirects the DSN to the
+// local listener once started.
+func TestForDSN_RedirectsEndpoint(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from postgres")
+	defer destServer.Close()
+
+	pg := &postgres.Config{
+		Host:     "127.0.0.1",
+		Port:     destServer.Addr().(*net.TCPAddr).Port,
+		User:     "app",
+		Password: "secret",
+		Database: "app",
+	}
+
+	tun, err := ForDSN(cfg, pg, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tun.RemoteAddr() != destServer.Addr().String() {
+		t.Errorf("expected remote addr %s, got %s", destServer.Addr().String(), tun.RemoteAddr())
+	}
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	host, port := pg.HostPort()
+	if host != "127.0.0.1" || port != tun.LocalPort() {
+		t.Errorf("expected endpoint 127.0.0.1:%d, got %s:%d", tun.LocalPort(), host, port)
+	}
+
+	if got := readFromTunnel(t, net.JoinHostPort(host, strconv.Itoa(port))); got != "hello from postgres" {
+		t.Errorf("expected 'hello from postgres', got '%s'", got)
+	}
+}
+
+// TestForDSN_DefaultPort verifies that a DSN without a port is tunneled to the database default port.
+func TestForDSN_DefaultPort(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+
+	tun, err := ForDSN(cfg, &oracle.StandaloneConfig{Host: "oracle.internal"}, 1521)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tun.RemoteAddr() != "oracle.internal:1521" {
+		t.Errorf("expected remote addr oracle.internal:1521, got %s", tun.RemoteAddr())
+	}
+}
+
+// TestForDSN_Invalid verifies that a missing endpoint, one without host or one whose port is only known to the
+// driver is rejected.
+func TestForDSN_Invalid(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+
+	if _, err := ForDSN(cfg, nil, 0); err == nil {
+		t.Error("expected error for nil endpoint")
+	}
+
+	if _, err := ForDSN(cfg, &postgres.Config{}, 0); err == nil {
+		t.Error("expected error for endpoint without host")
+	}
+
+	if _, err := ForDSN(cfg, &sqlserver.Config{Host: "mssql.internal", Instance: "SQLEXPRESS"}, 0); err == nil {
+		t.Error("expected error for a named instance without port")
+	}
+}
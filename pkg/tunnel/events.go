@@ -0,0 +1,107 @@
+package tunnel
+
+import (
+	"net"
+	"time"
+)
+
+// statsNotifyInterval is the minimum delay between two calls of the OnStats callback.
+const statsNotifyInterval = 500 * time.Millisecond
+
+// ConnEventType defines whether a ConnEvent reports a connection being opened or closed.
+type ConnEventType string
+
+const (
+	ConnOpened ConnEventType = "opened"
+	ConnClosed ConnEventType = "closed"
+)
+
+// ConnEvent describes a connection accepted by the tunnel. Byte counts, Duration and Err are only set on ConnClosed.
+type ConnEvent struct {
+	Type       ConnEventType
+	RemoteAddr string
+	OpenedAt   time.Time
+	BytesIn    int64
+	BytesOut   int64
+	Duration   time.Duration
+	Err        error
+}
+
+// OnConnection registers fn to be called whenever the tunnel opens or closes a forwarded connection, replacing any
+// previous callback. fn is called from the connection goroutines without holding the tunnel lock, so it may call
+// back into the tunnel. A nil fn removes the callback.
+func (t *Tunnel) OnConnection(fn func(ConnEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onConnection = fn
+}
+
+// OnStats registers fn to be called with a Stats snapshot whenever the statistics change, at most once every
+// statsNotifyInterval, replacing any previous callback. fn is called without holding the tunnel lock.
+// The running stats ticker reads the callback on every tick, so it takes effect immediately, without a restart.
+// A nil fn removes the callback.
+func (t *Tunnel) OnStats(fn func(Stats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onStats = fn
+}
+
+// OnReady registers fn to be called once per successful Start with the actual local port, right after the listener
+// is bound and before any connection is accepted, replacing any previous callback. It is useful with a localPort of
+// 0 to learn the port picked by the system. For reverse tunnels the port is 0. A nil fn removes the callback.
+func (t *Tunnel) OnReady(fn func(localPort int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onReady = fn
+}
+
+// notifyConn calls the OnConnection callback, if any, with ev.
+func (t *Tunnel) notifyConn(ev ConnEvent) {
+	t.mu.RLock()
+	fn := t.onConnection
+	t.mu.RUnlock()
+
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// dropConn closes a connection accepted by the run identified by done that could not be forwarded and reports it as
+// closed with err.
+func (t *Tunnel) dropConn(conn net.Conn, ev ConnEvent, err error, done chan struct{}) {
+	_ = conn.Close()
+
+	t.releaseConn(done)
+
+	ev.Type = ConnClosed
+	ev.Duration = t.now().Sub(ev.OpenedAt)
+	ev.Err = err
+	t.notifyConn(ev)
+}
+
+// watchStats calls the OnStats callback with the current statistics whenever they changed, until done is closed.
+func (t *Tunnel) watchStats(done chan struct{}) {
+	ticker := time.NewTicker(statsNotifyInterval)
+	defer ticker.Stop()
+
+	var last Stats
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		t.mu.RLock()
+		fn := t.onStats
+		stats := t.stats
+		t.mu.RUnlock()
+
+		if fn == nil || stats == last {
+			continue
+		}
+
+		last = stats
+		fn(stats)
+	}
+}
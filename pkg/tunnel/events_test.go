@@ -0,0 +1,192 @@
+package tunnel
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestOnConnection_ReportsOpenAndClose verifies that the connection callback receives an opened and a closed event
+// with the transferred bytes, and that it may call back into the tunnel.
+func TestOnConnection_ReportsOpenAndClose(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from oracle")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+
+	var mu sync.Mutex
+	var events []ConnEvent
+	closed := make(chan struct{})
+	tun.OnConnection(func(ev ConnEvent) {
+		// Calling back into the tunnel must not deadlock.
+		_ = tun.Stats()
+
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+
+		if ev.Type == ConnClosed {
+			close(closed)
+		}
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if got := readFromTunnel(t, tun.LocalAddr()); got != "hello from oracle" {
+		t.Fatalf("expected 'hello from oracle', got '%s'", got)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for closed event")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if events[0].Type != ConnOpened || events[0].RemoteAddr == "" {
+		t.Errorf("unexpected opened event: %+v", events[0])
+	}
+
+	if events[1].BytesIn != int64(len("hello from oracle")) {
+		t.Errorf("expected %d bytes in, got %d", len("hello from oracle"), events[1].BytesIn)
+	}
+
+	if events[1].RemoteAddr != events[0].RemoteAddr {
+		t.Errorf("expected remote addr %s, got %s", events[0].RemoteAddr, events[1].RemoteAddr)
+	}
+}
+
+// TestOnConnection_DialFailure verifies that a connection that cannot be forwarded is reported closed with an error.
+func TestOnConnection_DialFailure(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", freeTestPort(t), 0)
+
+	closed := make(chan ConnEvent, 1)
+	tun.OnConnection(func(ev ConnEvent) {
+		if ev.Type == ConnClosed {
+			closed <- ev
+		}
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	readFromTunnel(t, tun.LocalAddr())
+
+	select {
+	case ev := <-closed:
+		if ev.Err == nil {
+			t.Error("expected closed event to carry the dial error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for closed event")
+	}
+}
+
+// TestOnStats_NotifiesChanges verifies that the stats callback is called after the statistics change.
+func TestOnStats_NotifiesChanges(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from oracle")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+
+	updates := make(chan Stats, 16)
+	tun.OnStats(func(stats Stats) {
+		_ = tun.Status()
+		updates <- stats
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	readFromTunnel(t, tun.LocalAddr())
+
+	deadline := time.After(3 * time.Second)
+	for {
+		select {
+		case stats := <-updates:
+			if stats.Connections == 1 && stats.BytesIn > 0 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for stats update")
+		}
+	}
+}
+
+// TestOnReady_ReportsEphemeralPort verifies that the ready callback receives the port picked by the system exactly
+// once per Start, before Start returns.
+func TestOnReady_ReportsEphemeralPort(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	var ports []int
+	tun.OnReady(func(localPort int) {
+		// Calling back into the tunnel must not deadlock.
+		_ = tun.Status()
+		ports = append(ports, localPort)
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if len(ports) != 1 {
+		t.Fatalf("expected 1 ready call, got %d", len(ports))
+	}
+
+	if ports[0] == 0 || ports[0] != tun.LocalPort() {
+		t.Errorf("expected ready port %d, got %d", tun.LocalPort(), ports[0])
+	}
+
+	if err := tun.Restart(); err != nil {
+		t.Fatalf("unexpected error on restart: %v", err)
+	}
+
+	if len(ports) != 2 {
+		t.Errorf("expected 2 ready calls after restart, got %d", len(ports))
+	}
+}
+
+// TestOnReady_NotCalledOnFailure verifies that the ready callback is not called when Start fails.
+func TestOnReady_NotCalledOnFailure(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "127.0.0.1", freeTestPort(t))
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	called := false
+	tun.OnReady(func(int) { called = true })
+
+	if err := tun.Start(); err == nil {
+		tun.Close()
+		t.Fatal("expected error when the ssh server is unreachable")
+	}
+
+	if called {
+		t.Error("expected ready callback not to be called")
+	}
+}
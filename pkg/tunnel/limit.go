@@ -0,0 +1,78 @@
+package tunnel
+
+import "net"
+
+// LimitMode defines what the tunnel does with new connections once MaxConnections are active.
+type LimitMode string
+
+const (
+	// LimitReject closes new connections immediately and counts them in Stats.RejectedConnections.
+	LimitReject LimitMode = "reject"
+
+	// LimitQueue stops accepting new connections until a slot frees, leaving them in the listener backlog.
+	LimitQueue LimitMode = "queue"
+)
+
+// SetMaxConnections limits the number of connections forwarded at the same time to max, handling the excess according
+// to mode. A max of 0 or less removes the limit. It takes effect immediately.
+func (t *Tunnel) SetMaxConnections(max int, mode LimitMode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxConnections = max
+	t.limitMode = mode
+	t.slotFreed.Broadcast()
+}
+
+// waitForSlot blocks while the tunnel is limited in queue mode and has no free connection slot, returning false once
+// the run identified by done has ended.
+func (t *Tunnel) waitForSlot(done chan struct{}) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.done == done && t.limitMode == LimitQueue && t.atConnectionLimit() {
+		t.slotFreed.Wait()
+	}
+
+	return t.done == done
+}
+
+// admitConn registers conn as an active connection. It instead closes conn if the tunnel is draining, or if it is
+// limited in reject mode and has no free connection slot, counting it as rejected. It reports whether conn was admitted.
+func (t *Tunnel) admitConn(conn net.Conn) bool {
+	t.mu.Lock()
+	admitted := !t.draining && (t.limitMode == LimitQueue || !t.atConnectionLimit())
+	if admitted {
+		t.stats.Connections++
+		t.stats.ActiveConnections++
+		t.stats.LastActivity = t.now()
+	} else if !t.draining {
+		t.stats.RejectedConnections++
+	}
+	draining := t.draining
+	t.mu.Unlock()
+
+	if !admitted {
+		t.logger().Warn("connection refused", "remote_addr", conn.RemoteAddr().String(), "draining", draining)
+		_ = conn.Close()
+	}
+
+	return admitted
+}
+
+// atConnectionLimit reports whether every connection slot is in use. It must be called with t.mu held.
+func (t *Tunnel) atConnectionLimit() bool {
+	return t.maxConnections > 0 && t.stats.ActiveConnections >= int64(t.maxConnections)
+}
+
+// releaseConn marks a connection forwarded by the run identified by done as finished, freeing its connection slot.
+// Connections outliving their run are ignored, since the statistics were reset when it stopped.
+func (t *Tunnel) releaseConn(done chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done != done {
+		return
+	}
+	t.stats.ActiveConnections--
+	t.slotFreed.Broadcast()
+}
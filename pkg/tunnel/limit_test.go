@@ -0,0 +1,249 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setupHeldDestinationServer starts a destination server that answers "done" to each connection once release is closed.
+func setupHeldDestinationServer(t *testing.T, release chan struct{}) net.Listener {
+	t.Helper()
+	return setupTestDestinationServerFunc(t, func(conn net.Conn) {
+		<-release
+		conn.Write([]byte("done"))
+		conn.Close()
+	})
+}
+
+// waitForActiveConnections polls the tunnel until it has want active connections or fails the test after a timeout.
+func waitForActiveConnections(t *testing.T, tun *Tunnel, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if tun.Stats().ActiveConnections == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("expected %d active connections, got %d", want, tun.Stats().ActiveConnections)
+}
+
+// TestMaxConnections_Reject verifies that connections over the limit are closed immediately and counted as rejected.
+func TestMaxConnections_Reject(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+	defer close(release)
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetMaxConnections(2, LimitReject)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", tun.LocalAddr())
+		if err != nil {
+			t.Fatalf("failed to connect to tunnel: %v", err)
+		}
+		defer conn.Close()
+	}
+	waitForActiveConnections(t, tun, 2)
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected rejected connection to be closed, got %v", err)
+	}
+
+	stats := tun.Stats()
+	if stats.RejectedConnections != 1 {
+		t.Errorf("expected 1 rejected connection, got %d", stats.RejectedConnections)
+	}
+
+	if stats.ActiveConnections != 2 {
+		t.Errorf("expected 2 active connections, got %d", stats.ActiveConnections)
+	}
+}
+
+// TestMaxConnections_Queue verifies that connections over the limit wait until a slot frees.
+func TestMaxConnections_Queue(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetMaxConnections(1, LimitQueue)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	first, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer first.Close()
+	waitForActiveConnections(t, tun, 1)
+
+	second, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer second.Close()
+
+	time.Sleep(200 * time.Millisecond)
+
+	if tun.Stats().Connections != 1 {
+		t.Errorf("expected queued connection not to be accepted yet, got %d connections", tun.Stats().Connections)
+	}
+
+	close(release)
+
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 16)
+	n, err := second.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(buf[:n]) != "done" {
+		t.Errorf("expected 'done', got '%s'", string(buf[:n]))
+	}
+
+	if tun.Stats().RejectedConnections != 0 {
+		t.Errorf("expected no rejected connections, got %d", tun.Stats().RejectedConnections)
+	}
+}
+
+// TestMaxConnections_StopWhileQueued verifies that Stop does not hang while the accept loop waits for a slot.
+func TestMaxConnections_StopWhileQueued(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+	defer close(release)
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetMaxConnections(1, LimitQueue)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+	waitForActiveConnections(t, tun, 1)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- tun.Stop() }()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("unexpected error on stop: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop hung while the accept loop was waiting for a slot")
+	}
+}
+
+// TestMaxConnections_HoldsAfterRestart verifies that a connection admitted before a restart but closed after it does
+// not free a slot in the next run.
+func TestMaxConnections_HoldsAfterRestart(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+	defer close(release)
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetMaxConnections(1, LimitReject)
+
+	// Hold the first connection between its admission and its pipe until the tunnel has restarted.
+	opened := make(chan struct{})
+	restarted := make(chan struct{})
+	var once sync.Once
+	tun.OnConnection(func(ev ConnEvent) {
+		if ev.Type == ConnOpened {
+			once.Do(func() {
+				close(opened)
+				<-restarted
+			})
+		}
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	old, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer old.Close()
+	<-opened
+
+	if err := tun.Restart(); err != nil {
+		t.Fatalf("unexpected error on restart: %v", err)
+	}
+	close(restarted)
+
+	// Let the connection of the previous run be piped and then finish in the new run.
+	time.Sleep(50 * time.Millisecond)
+	old.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	if active := tun.Stats().ActiveConnections; active != 0 {
+		t.Fatalf("expected 0 active connections after restart, got %d", active)
+	}
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+	waitForActiveConnections(t, tun, 1)
+
+	extra, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer extra.Close()
+
+	extra.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := extra.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("expected the connection over the limit to be closed, got %v", err)
+	}
+
+	if rejected := tun.Stats().RejectedConnections; rejected != 1 {
+		t.Errorf("expected 1 rejected connection, got %d", rejected)
+	}
+}
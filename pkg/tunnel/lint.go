@@ -0,0 +1,29 @@
+package tunnel
+
+import "github.com/pperesbr/gokit/pkg/dsn"
+
+var _ dsn.Linter = (*SSHConfig)(nil)
+
+// Lint reports best-practice advisories for the configuration: disabled host key
+// verification and password-only authentication.
+func (c *SSHConfig) Lint() []dsn.Warning {
+	var warnings []dsn.Warning
+
+	if c.Insecure && c.IsInsecure() {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "insecure",
+			Severity: dsn.SeverityWarning,
+			Message:  "host key verification is disabled",
+		})
+	}
+
+	if c.Password != "" && c.KeyFile == "" && !c.UseAgent {
+		warnings = append(warnings, dsn.Warning{
+			Field:    "password",
+			Severity: dsn.SeverityInfo,
+			Message:  "password authentication is used; prefer a key file or ssh-agent",
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,49 @@
+package tunnel
+
+import (
+	"testing"
+
+	"github.com/pperesbr/gokit/pkg/dsn"
+)
+
+func TestSSHConfig_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SSHConfig
+		want   map[string]dsn.Severity
+	}{
+		{
+			name:   "key file with known hosts",
+			config: SSHConfig{KeyFile: "/home/user/.ssh/id_ed25519", KnownHostsFile: "/home/user/.ssh/known_hosts"},
+			want:   map[string]dsn.Severity{},
+		},
+		{
+			name:   "insecure with password",
+			config: SSHConfig{Password: "secret", Insecure: true},
+			want: map[string]dsn.Severity{
+				"insecure": dsn.SeverityWarning,
+				"password": dsn.SeverityInfo,
+			},
+		},
+		{
+			name:   "insecure overridden by known hosts",
+			config: SSHConfig{UseAgent: true, Insecure: true, KnownHostsFile: "/home/user/.ssh/known_hosts"},
+			want:   map[string]dsn.Severity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dsn.Warnings(tt.config.Lint()).Fields()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() fields = %v, want %v", got, tt.want)
+			}
+
+			for field, severity := range tt.want {
+				if got[field] != severity {
+					t.Errorf("field %s: severity = %q, want %q", field, got[field], severity)
+				}
+			}
+		})
+	}
+}
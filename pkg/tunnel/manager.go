@@ -0,0 +1,283 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTunnelExists is returned by Manager.Add when a tunnel is already registered under the name.
+var ErrTunnelExists = errors.New("a tunnel with this name already exists")
+
+// Manager starts, stops and reports on a set of named tunnels, e.g. the bastioned connections of an application.
+type Manager struct {
+	tunnels map[string]*Tunnel
+	names   []string
+
+	// breaker is set by EnableCircuitBreaker; breakers holds the state of each tunnel while it is set.
+	breaker       *breakerPolicy
+	breakers      map[string]*Breaker
+	checkInterval time.Duration
+
+	// supervising is closed by StopAll to end the goroutine restarting failed tunnels, which closes supervised.
+	supervising chan struct{}
+	supervised  chan struct{}
+
+	// now and jitter are replaced by tests to get deterministic retry times.
+	now    func() time.Time
+	jitter func(time.Duration) time.Duration
+
+	mu sync.RWMutex
+}
+
+// NewManager initializes a Manager with no tunnels.
+func NewManager() *Manager {
+	return &Manager{
+		tunnels:       make(map[string]*Tunnel),
+		breakers:      make(map[string]*Breaker),
+		checkInterval: defaultBreakerCheckInterval,
+		now:           time.Now,
+		// Each delay is spread over [d/2, d].
+		jitter: func(d time.Duration) time.Duration { return jitter(d*3/4, d/4) },
+	}
+}
+
+// Add registers t under name. It returns ErrTunnelExists if name is already taken. Adding a tunnel does not start it.
+func (m *Manager) Add(name string, t *Tunnel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tunnels[name]; ok {
+		return fmt.Errorf("%w: %s", ErrTunnelExists, name)
+	}
+
+	m.tunnels[name] = t
+	m.names = append(m.names, name)
+
+	return nil
+}
+
+// Get returns the tunnel registered under name.
+func (m *Manager) Get(name string) (*Tunnel, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.tunnels[name]
+	return t, ok
+}
+
+// Names returns the names of the registered tunnels, in registration order.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.names...)
+}
+
+// EnableCircuitBreaker makes StartAll keep restarting tunnels that fail to start or later fail, until StopAll.
+// A failed tunnel is retried after about baseDelay; once it has failed threshold times in a row its breaker opens and
+// the delay doubles with every further failure up to maxDelay. Every delay is jittered so tunnels sharing a bastion
+// do not retry in lockstep. A successful restart closes the breaker.
+func (m *Manager) EnableCircuitBreaker(threshold int, baseDelay, maxDelay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.breaker = &breakerPolicy{
+		threshold: max(threshold, 1),
+		baseDelay: baseDelay,
+		maxDelay:  max(maxDelay, baseDelay),
+	}
+}
+
+// StartAll starts every registered tunnel that is not already running, concurrently. It waits for all of them and
+// returns the failures joined, each prefixed with the tunnel's name. With EnableCircuitBreaker, the failed tunnels
+// keep being retried in the background.
+func (m *Manager) StartAll() error {
+	return m.StartAllContext(context.Background())
+}
+
+// StartAllContext starts the tunnels like StartAll, aborting the SSH dials and handshakes when ctx is done.
+func (m *Manager) StartAllContext(ctx context.Context) error {
+	errs := m.each(func(name string, t *Tunnel) error {
+		if t.Status() == StatusRunning {
+			return nil
+		}
+
+		err := t.StartContext(ctx)
+		m.record(name, err)
+		return err
+	})
+
+	m.mu.Lock()
+	if m.breaker != nil && m.supervising == nil {
+		m.supervising = make(chan struct{})
+		m.supervised = make(chan struct{})
+		go m.supervise(m.supervising, m.supervised)
+	}
+	m.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// StopAll stops every registered tunnel concurrently and returns the failures joined, each prefixed with the
+// tunnel's name. It first stops restarting failed tunnels. Stopping an already stopped tunnel is a no-op, so StopAll
+// is safe to call repeatedly during shutdown.
+func (m *Manager) StopAll() error {
+	m.mu.Lock()
+	supervising, supervised := m.supervising, m.supervised
+	m.supervising, m.supervised = nil, nil
+	m.mu.Unlock()
+
+	if supervising != nil {
+		close(supervising)
+		<-supervised
+	}
+
+	errs := m.each(func(_ string, t *Tunnel) error {
+		return t.Stop()
+	})
+
+	m.mu.Lock()
+	clear(m.breakers)
+	m.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// ManagedStatus is the status of a tunnel registered in a Manager together with its circuit breaker.
+type ManagedStatus struct {
+	Status  Status
+	Breaker Breaker
+}
+
+// Statuses returns the current status and circuit breaker of every registered tunnel, keyed by name. All breakers are
+// closed unless EnableCircuitBreaker is used.
+func (m *Manager) Statuses() map[string]ManagedStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make(map[string]ManagedStatus, len(m.tunnels))
+	for name, t := range m.tunnels {
+		status := ManagedStatus{Status: t.Status(), Breaker: Breaker{State: BreakerClosed}}
+		if b, ok := m.breakers[name]; ok {
+			status.Breaker = *b
+		}
+		statuses[name] = status
+	}
+
+	return statuses
+}
+
+// each runs fn on every registered tunnel concurrently and returns the errors, prefixed with the tunnel's name, in
+// registration order.
+func (m *Manager) each(fn func(name string, t *Tunnel) error) []error {
+	m.mu.RLock()
+	names := append([]string(nil), m.names...)
+	tunnels := make([]*Tunnel, len(names))
+	for i, name := range names {
+		tunnels[i] = m.tunnels[name]
+	}
+	m.mu.RUnlock()
+
+	errs := make([]error, len(tunnels))
+
+	var wg sync.WaitGroup
+	for i, t := range tunnels {
+		wg.Go(func() {
+			if err := fn(names[i], t); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", names[i], err)
+			}
+		})
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// record updates the breaker of name after a start attempt: a success closes it, a failure counts towards opening it
+// and schedules the next retry.
+func (m *Manager) record(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.breaker == nil {
+		return
+	}
+
+	if err == nil {
+		delete(m.breakers, name)
+		return
+	}
+
+	b, ok := m.breakers[name]
+	if !ok {
+		b = &Breaker{}
+		m.breakers[name] = b
+	}
+
+	b.Failures++
+	b.State = m.breaker.state(b.Failures)
+	b.RetryAt = m.now().Add(m.jitter(m.breaker.delay(b.Failures)))
+}
+
+// supervise restarts failed tunnels until stop is closed, then closes done.
+func (m *Manager) supervise(stop, done chan struct{}) {
+	defer close(done)
+
+	m.mu.RLock()
+	interval := m.checkInterval
+	m.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.restartFailed()
+		}
+	}
+}
+
+// restartFailed records newly failed tunnels and restarts those whose retry time has come, concurrently.
+func (m *Manager) restartFailed() {
+	now := m.now()
+
+	var due, failed []string
+
+	m.mu.RLock()
+	for _, name := range m.names {
+		if m.tunnels[name].Status() != StatusError {
+			continue
+		}
+
+		if b, ok := m.breakers[name]; ok && !now.Before(b.RetryAt) {
+			due = append(due, name)
+		} else if !ok {
+			failed = append(failed, name)
+		}
+	}
+	m.mu.RUnlock()
+
+	// A tunnel that failed while running is counted first, so its first retry waits for the base delay.
+	for _, name := range failed {
+		t, _ := m.Get(name)
+		m.record(name, t.LastError())
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range due {
+		t, _ := m.Get(name)
+		wg.Go(func() {
+			err := t.Start()
+			if err != nil {
+				t.logger().Warn("restart by manager failed", "tunnel", name, "error", err)
+			}
+			m.record(name, err)
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,220 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newManagerTestTunnel starts its own SSH server and destination server and returns a tunnel between them.
+func newManagerTestTunnel(t *testing.T, response string) *Tunnel {
+	t.Helper()
+
+	sshServer, cfg := setupTestSSHServer(t)
+	t.Cleanup(func() { sshServer.Close() })
+
+	destServer := setupTestDestinationServer(t, response)
+	t.Cleanup(func() { destServer.Close() })
+
+	return NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+}
+
+func TestManager_StartAllStopAll(t *testing.T) {
+	m := NewManager()
+	orders := newManagerTestTunnel(t, "orders")
+	billing := newManagerTestTunnel(t, "billing")
+
+	if err := m.Add("orders", orders); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := m.Add("billing", billing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := m.StartAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.StopAll()
+
+	for name, status := range m.Statuses() {
+		if status.Status != StatusRunning {
+			t.Errorf("%s: expected status running, got %s", name, status.Status)
+		}
+	}
+
+	for _, name := range []string{"orders", "billing"} {
+		tun, ok := m.Get(name)
+		if !ok {
+			t.Fatalf("tunnel %s not found", name)
+		}
+
+		conn, err := net.Dial("tcp", tun.LocalAddr())
+		if err != nil {
+			t.Fatalf("%s: failed to connect: %v", name, err)
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		got, _ := io.ReadAll(conn)
+		conn.Close()
+
+		if string(got) != name {
+			t.Errorf("%s: expected response %q, got %q", name, name, got)
+		}
+	}
+
+	if err := m.StopAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, status := range m.Statuses() {
+		if status.Status != StatusStopped {
+			t.Errorf("%s: expected status stopped, got %s", name, status.Status)
+		}
+	}
+
+	// A second StopAll during shutdown is a no-op.
+	if err := m.StopAll(); err != nil {
+		t.Errorf("unexpected error on second StopAll: %v", err)
+	}
+}
+
+func TestManager_StartAllAggregatesErrors(t *testing.T) {
+	m := NewManager()
+	_ = m.Add("good", newManagerTestTunnel(t, "ok"))
+
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "127.0.0.1", freeTestPort(t))
+	_ = m.Add("unreachable", NewTunnel(cfg, "127.0.0.1", 5432, 0))
+
+	err := m.StartAll()
+	defer m.StopAll()
+
+	if err == nil {
+		t.Fatal("expected error for unreachable tunnel")
+	}
+
+	if !strings.Contains(err.Error(), "unreachable:") {
+		t.Errorf("expected error prefixed with the tunnel name, got %v", err)
+	}
+
+	statuses := m.Statuses()
+	if statuses["good"].Status != StatusRunning {
+		t.Errorf("expected good tunnel running, got %s", statuses["good"].Status)
+	}
+	if statuses["unreachable"].Status != StatusError {
+		t.Errorf("expected unreachable tunnel in error, got %s", statuses["unreachable"].Status)
+	}
+}
+
+func TestManager_StartAllSkipsRunning(t *testing.T) {
+	m := NewManager()
+	tun := newManagerTestTunnel(t, "ok")
+	_ = m.Add("db", tun)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.StopAll()
+
+	if err := m.StartAll(); err != nil {
+		t.Errorf("expected running tunnel to be skipped, got %v", err)
+	}
+}
+
+func TestManager_AddDuplicate(t *testing.T) {
+	m := NewManager()
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "127.0.0.1", 22)
+
+	_ = m.Add("db", NewTunnel(cfg, "db.internal", 5432, 0))
+	if err := m.Add("db", NewTunnel(cfg, "db.internal", 5432, 0)); !errors.Is(err, ErrTunnelExists) {
+		t.Errorf("expected ErrTunnelExists, got %v", err)
+	}
+
+	if names := m.Names(); len(names) != 1 || names[0] != "db" {
+		t.Errorf("expected names [db], got %v", names)
+	}
+}
+
+func TestManager_GetMissing(t *testing.T) {
+	if _, ok := NewManager().Get("missing"); ok {
+		t.Error("expected missing tunnel not to be found")
+	}
+}
+
+func TestManager_CircuitBreaker(t *testing.T) {
+	m := NewManager()
+	m.checkInterval = 5 * time.Millisecond
+	m.jitter = func(d time.Duration) time.Duration { return d }
+	m.EnableCircuitBreaker(2, 10*time.Millisecond, 40*time.Millisecond)
+
+	healthy := newManagerTestTunnel(t, "healthy")
+	_ = m.Add("healthy", healthy)
+
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "127.0.0.1", freeTestPort(t))
+	_ = m.Add("unreachable", NewTunnel(cfg, "127.0.0.1", 5432, 0))
+
+	if err := m.StartAll(); err == nil {
+		t.Fatal("expected error for unreachable tunnel")
+	}
+	defer m.StopAll()
+
+	if b := m.Statuses()["unreachable"].Breaker; b.Failures != 1 || b.State != BreakerClosed {
+		t.Errorf("after StartAll: expected 1 failure and closed breaker, got %+v", b)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Statuses()["unreachable"].Breaker.Failures < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("manager did not retry the unreachable tunnel: %+v", m.Statuses()["unreachable"].Breaker)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	statuses := m.Statuses()
+	if b := statuses["unreachable"].Breaker; b.State != BreakerOpen {
+		t.Errorf("expected open breaker after repeated failures, got %+v", b)
+	}
+	if b := statuses["healthy"].Breaker; b.State != BreakerClosed || b.Failures != 0 {
+		t.Errorf("expected closed breaker for healthy tunnel, got %+v", b)
+	}
+
+	if err := m.StopAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b := m.Statuses()["unreachable"].Breaker; b.Failures != 0 || b.State != BreakerClosed {
+		t.Errorf("after StopAll: expected reset breaker, got %+v", b)
+	}
+}
+
+func TestManager_CircuitBreakerRestartsFailedTunnel(t *testing.T) {
+	m := NewManager()
+	m.checkInterval = 5 * time.Millisecond
+	m.jitter = func(d time.Duration) time.Duration { return d }
+	m.EnableCircuitBreaker(3, 10*time.Millisecond, time.Second)
+
+	sshServer, cfg := setupTestSSHServer(t)
+	destServer := setupTestDestinationServer(t, "ok")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	_ = m.Add("db", tun)
+
+	if err := m.StartAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer m.StopAll()
+
+	// Losing the SSH server fails the tunnel; the manager counts it and retries.
+	sshServer.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Statuses()["db"].Breaker.Failures < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("manager did not record the failure: status %s, breaker %+v", tun.Status(), m.Statuses()["db"].Breaker)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,87 @@
+// Package metrics exports tunnel statistics as Prometheus metrics. It is kept out of the tunnel package so only
+// programs that export metrics depend on the Prometheus client library.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pperesbr/gokit/pkg/tunnel"
+)
+
+// statuses lists the values reported by the status gauge, one series per status.
+var statuses = []tunnel.Status{
+	tunnel.StatusStopped,
+	tunnel.StatusStarting,
+	tunnel.StatusRunning,
+	tunnel.StatusError,
+}
+
+// Collector is a prometheus.Collector reporting the Stats and Status of a single tunnel. Register one Collector per
+// tunnel, telling them apart with constant labels.
+type Collector struct {
+	tunnel *tunnel.Tunnel
+
+	bytesIn             *prometheus.Desc
+	bytesOut            *prometheus.Desc
+	connections         *prometheus.Desc
+	activeConnections   *prometheus.Desc
+	rejectedConnections *prometheus.Desc
+	timedOutConnections *prometheus.Desc
+	reconnects          *prometheus.Desc
+	status              *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a Collector for t whose metrics carry labels, e.g. prometheus.Labels{"tunnel": "orders-db"}.
+func NewCollector(t *tunnel.Tunnel, labels prometheus.Labels) *Collector {
+	desc := func(name, help string, variableLabels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("gokit", "tunnel", name), help, variableLabels, labels)
+	}
+
+	return &Collector{
+		tunnel:              t,
+		bytesIn:             desc("bytes_in_total", "Bytes received from the forwarded connections' targets."),
+		bytesOut:            desc("bytes_out_total", "Bytes sent to the forwarded connections' targets."),
+		connections:         desc("connections_total", "Connections accepted by the tunnel."),
+		activeConnections:   desc("active_connections", "Connections currently being forwarded."),
+		rejectedConnections: desc("rejected_connections_total", "Connections rejected by the connection limit."),
+		timedOutConnections: desc("timed_out_connections_total", "Connections closed by the connection idle timeout."),
+		reconnects:          desc("reconnects_total", "Successful reconnections of the SSH connection."),
+		status:              desc("status", "1 for the tunnel's current status, 0 for the others.", "status"),
+	}
+}
+
+// Describe sends the descriptors of the tunnel metrics to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bytesIn
+	ch <- c.bytesOut
+	ch <- c.connections
+	ch <- c.activeConnections
+	ch <- c.rejectedConnections
+	ch <- c.timedOutConnections
+	ch <- c.reconnects
+	ch <- c.status
+}
+
+// Collect sends the tunnel's current statistics and status to ch.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.tunnel.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.bytesIn, prometheus.CounterValue, float64(stats.BytesIn))
+	ch <- prometheus.MustNewConstMetric(c.bytesOut, prometheus.CounterValue, float64(stats.BytesOut))
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.CounterValue, float64(stats.Connections))
+	ch <- prometheus.MustNewConstMetric(c.activeConnections, prometheus.GaugeValue, float64(stats.ActiveConnections))
+	ch <- prometheus.MustNewConstMetric(c.rejectedConnections, prometheus.CounterValue, float64(stats.RejectedConnections))
+	ch <- prometheus.MustNewConstMetric(c.timedOutConnections, prometheus.CounterValue, float64(stats.TimedOutConnections))
+	ch <- prometheus.MustNewConstMetric(c.reconnects, prometheus.CounterValue, float64(stats.Reconnects))
+
+	current := c.tunnel.Status()
+	for _, s := range statuses {
+		value := 0.0
+		if s == current {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.status, prometheus.GaugeValue, value, string(s))
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pperesbr/gokit/pkg/tunnel"
+)
+
+func newTestTunnel(t *testing.T) *tunnel.Tunnel {
+	t.Helper()
+
+	cfg, err := tunnel.NewInsecureSSHConfig("user", "password", "", "127.0.0.1", 22)
+	if err != nil {
+		t.Fatalf("failed to create ssh config: %v", err)
+	}
+
+	return tunnel.NewTunnel(cfg, "db.internal", 5432, 0)
+}
+
+func TestCollector_Scrape(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(NewCollector(newTestTunnel(t), prometheus.Labels{"tunnel": "orders"})); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	want := `
+# HELP gokit_tunnel_active_connections Connections currently being forwarded.
+# TYPE gokit_tunnel_active_connections gauge
+gokit_tunnel_active_connections{tunnel="orders"} 0
+# HELP gokit_tunnel_bytes_in_total Bytes received from the forwarded connections' targets.
+# TYPE gokit_tunnel_bytes_in_total counter
+gokit_tunnel_bytes_in_total{tunnel="orders"} 0
+# HELP gokit_tunnel_connections_total Connections accepted by the tunnel.
+# TYPE gokit_tunnel_connections_total counter
+gokit_tunnel_connections_total{tunnel="orders"} 0
+# HELP gokit_tunnel_status 1 for the tunnel's current status, 0 for the others.
+# TYPE gokit_tunnel_status gauge
+gokit_tunnel_status{status="error",tunnel="orders"} 0
+gokit_tunnel_status{status="running",tunnel="orders"} 0
+gokit_tunnel_status{status="starting",tunnel="orders"} 0
+gokit_tunnel_status{status="stopped",tunnel="orders"} 1
+`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"gokit_tunnel_active_connections",
+		"gokit_tunnel_bytes_in_total",
+		"gokit_tunnel_connections_total",
+		"gokit_tunnel_status",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCollector_MetricCount(t *testing.T) {
+	c := NewCollector(newTestTunnel(t), nil)
+
+	// Seven statistics plus one status series per status.
+	if got, want := testutil.CollectAndCount(c), 7+len(statuses); got != want {
+		t.Errorf("collected %d metrics, want %d", got, want)
+	}
+}
+
+func TestCollector_MultipleTunnels(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	for _, name := range []string{"orders", "billing"} {
+		if err := reg.Register(NewCollector(newTestTunnel(t), prometheus.Labels{"tunnel": name})); err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+
+	if got := testutil.CollectAndCount(reg, "gokit_tunnel_connections_total"); got != 2 {
+		t.Errorf("expected one connections_total series per tunnel, got %d", got)
+	}
+}
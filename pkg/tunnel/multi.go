@@ -0,0 +1,215 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// MultiTunnel forwards several local ports to remote addresses over a single shared SSH connection.
+// Each forward is exposed as a Tunnel with its own listener, status and statistics.
+type MultiTunnel struct {
+	config   *SSHConfig
+	forwards []*Tunnel
+
+	client    *ssh.Client
+	status    Status
+	lastError error
+
+	done chan struct{}
+	mu   sync.RWMutex
+}
+
+// NewMultiTunnel initializes a MultiTunnel with the provided SSHConfig and no forwards.
+func NewMultiTunnel(config *SSHConfig) *MultiTunnel {
+	return &MultiTunnel{
+		config: config,
+		status: StatusStopped,
+	}
+}
+
+// AddForward registers a forward from localPort to remoteHost:remotePort and returns its Tunnel. If the MultiTunnel
+// is already running, the forward is started immediately over the shared SSH connection.
+// The returned Tunnel is managed by the MultiTunnel and should not be started or stopped directly.
+func (m *MultiTunnel) AddForward(remoteHost string, remotePort, localPort int) (*Tunnel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fwd := NewTunnel(m.config, remoteHost, remotePort, localPort)
+	fwd.shared = true
+
+	if err := fwd.Validate(); err != nil {
+		return nil, err
+	}
+
+	if m.status == StatusRunning {
+		if err := startForward(context.Background(), fwd, m.client); err != nil {
+			return nil, err
+		}
+	}
+
+	m.forwards = append(m.forwards, fwd)
+
+	return fwd, nil
+}
+
+// Forwards returns the tunnels registered through AddForward, in registration order.
+func (m *MultiTunnel) Forwards() []*Tunnel {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*Tunnel(nil), m.forwards...)
+}
+
+// Start opens the shared SSH connection and starts every registered forward. Returns an error if any of them fails.
+func (m *MultiTunnel) Start() error {
+	return m.StartContext(context.Background())
+}
+
+// StartContext starts the MultiTunnel like Start, aborting the SSH dial and the listeners setup when ctx is done.
+func (m *MultiTunnel) StartContext(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status == StatusRunning {
+		return fmt.Errorf("tunnel is already running")
+	}
+
+	if m.config == nil {
+		return m.setError(fmt.Errorf("config is required"))
+	}
+
+	if len(m.forwards) == 0 {
+		return m.setError(fmt.Errorf("at least one forward is required"))
+	}
+
+	m.status = StatusStarting
+	m.lastError = nil
+
+	client, err := dialSSH(ctx, m.config)
+	if err != nil {
+		return m.setError(fmt.Errorf("failed to connect to ssh server: %w", err))
+	}
+
+	for i, fwd := range m.forwards {
+		if err := startForward(ctx, fwd, client); err != nil {
+			for _, started := range m.forwards[:i] {
+				_ = started.Stop()
+			}
+			_ = client.Close()
+			return m.setError(err)
+		}
+	}
+
+	m.client = client
+	m.status = StatusRunning
+	m.done = make(chan struct{})
+
+	go m.watch(client, m.done)
+
+	return nil
+}
+
+// Stop closes every forward listener and the shared SSH connection.
+func (m *MultiTunnel) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status == StatusStopped {
+		return nil
+	}
+
+	if m.done != nil {
+		close(m.done)
+		m.done = nil
+	}
+
+	var errs []error
+	for _, fwd := range m.forwards {
+		if err := fwd.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if m.client != nil {
+		if err := m.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close ssh client: %w", err))
+		}
+		m.client = nil
+	}
+
+	m.status = StatusStopped
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors stopping tunnel: %v", errs)
+	}
+
+	return nil
+}
+
+// Close gracefully shuts down every forward and the shared SSH connection.
+func (m *MultiTunnel) Close() error {
+	return m.Stop()
+}
+
+// Status returns the current operational state of the shared SSH connection in a thread-safe manner.
+func (m *MultiTunnel) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// LastError retrieves the last recorded error encountered by the MultiTunnel in a thread-safe manner.
+func (m *MultiTunnel) LastError() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastError
+}
+
+// setError records err as the last error and moves the MultiTunnel to the error state. The caller must hold m.mu.
+func (m *MultiTunnel) setError(err error) error {
+	m.status = StatusError
+	m.lastError = err
+	return err
+}
+
+// watch waits for the shared SSH connection to drop and, unless the MultiTunnel was stopped, fails every forward.
+func (m *MultiTunnel) watch(client *ssh.Client, done chan struct{}) {
+	err := client.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.done != done {
+		return
+	}
+
+	close(m.done)
+	m.done = nil
+
+	err = fmt.Errorf("ssh connection lost: %w", err)
+	for _, fwd := range m.forwards {
+		fwd.mu.RLock()
+		fwdDone := fwd.done
+		fwd.mu.RUnlock()
+		fwd.fail(fwdDone, err)
+	}
+
+	m.client = nil
+	_ = m.setError(err)
+}
+
+// startForward starts fwd as a forward of a MultiTunnel over the shared client.
+func startForward(ctx context.Context, fwd *Tunnel, client *ssh.Client) error {
+	if client == nil {
+		return fmt.Errorf("ssh client is not connected")
+	}
+
+	if err := fwd.begin(); err != nil {
+		return err
+	}
+
+	_, err := fwd.listen(ctx, client)
+	return err
+}
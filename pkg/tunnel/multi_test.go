@@ -0,0 +1,185 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// readFromTunnel dials addr and returns everything read before the connection is closed or times out.
+func readFromTunnel(t *testing.T, addr string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	return string(buf[:n])
+}
+
+// TestMultiTunnel_ForwardsShareConnection verifies that several forwards work over a single SSH connection.
+func TestMultiTunnel_ForwardsShareConnection(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	oracle := setupTestDestinationServer(t, "hello from oracle")
+	defer oracle.Close()
+
+	app := setupTestDestinationServer(t, "hello from app")
+	defer app.Close()
+
+	multi := NewMultiTunnel(cfg)
+
+	oracleFwd, err := multi.AddForward("127.0.0.1", oracle.Addr().(*net.TCPAddr).Port, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	appFwd, err := multi.AddForward("127.0.0.1", app.Addr().(*net.TCPAddr).Port, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := multi.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer multi.Close()
+
+	if got := readFromTunnel(t, oracleFwd.LocalAddr()); got != "hello from oracle" {
+		t.Errorf("expected 'hello from oracle', got '%s'", got)
+	}
+
+	if got := readFromTunnel(t, appFwd.LocalAddr()); got != "hello from app" {
+		t.Errorf("expected 'hello from app', got '%s'", got)
+	}
+
+	server := sshServer.(*testSSHServer)
+	server.mu.Lock()
+	conns := len(server.conns)
+	server.mu.Unlock()
+
+	if conns != 1 {
+		t.Errorf("expected 1 ssh connection, got %d", conns)
+	}
+
+	if oracleFwd.Stats().Connections != 1 || appFwd.Stats().Connections != 1 {
+		t.Errorf("expected 1 connection per forward, got %d and %d", oracleFwd.Stats().Connections, appFwd.Stats().Connections)
+	}
+}
+
+// TestMultiTunnel_AddForwardWhileRunning verifies that a forward added to a running MultiTunnel starts immediately.
+func TestMultiTunnel_AddForwardWhileRunning(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	dest := setupTestDestinationServer(t, "late forward")
+	defer dest.Close()
+
+	multi := NewMultiTunnel(cfg)
+	if _, err := multi.AddForward("127.0.0.1", dest.Addr().(*net.TCPAddr).Port, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := multi.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer multi.Close()
+
+	fwd, err := multi.AddForward("127.0.0.1", dest.Addr().(*net.TCPAddr).Port, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fwd.Status() != StatusRunning {
+		t.Errorf("expected status %s, got %s", StatusRunning, fwd.Status())
+	}
+
+	if got := readFromTunnel(t, fwd.LocalAddr()); got != "late forward" {
+		t.Errorf("expected 'late forward', got '%s'", got)
+	}
+}
+
+// TestMultiTunnel_Stop verifies that Stop closes every forward listener.
+func TestMultiTunnel_Stop(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	multi := NewMultiTunnel(cfg)
+	fwdA, _ := multi.AddForward("127.0.0.1", 1521, 0)
+	fwdB, _ := multi.AddForward("127.0.0.1", 8080, 0)
+
+	if err := multi.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	addrA, addrB := fwdA.LocalAddr(), fwdB.LocalAddr()
+
+	if err := multi.Stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if multi.Status() != StatusStopped {
+		t.Errorf("expected status %s, got %s", StatusStopped, multi.Status())
+	}
+
+	for _, addr := range []string{addrA, addrB} {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			t.Errorf("expected listener %s to be closed", addr)
+		}
+	}
+}
+
+// TestMultiTunnel_NoForwards verifies that starting a MultiTunnel without forwards fails.
+func TestMultiTunnel_NoForwards(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	multi := NewMultiTunnel(cfg)
+
+	if err := multi.Start(); err == nil {
+		t.Fatal("expected error without forwards")
+	}
+
+	if multi.Status() != StatusError {
+		t.Errorf("expected status %s, got %s", StatusError, multi.Status())
+	}
+}
+
+// TestMultiTunnel_AddForwardInvalid verifies that AddForward validates the forward parameters.
+func TestMultiTunnel_AddForwardInvalid(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	multi := NewMultiTunnel(cfg)
+
+	if _, err := multi.AddForward("", 1521, 0); err == nil {
+		t.Fatal("expected error for empty remote host")
+	}
+}
+
+// TestMultiTunnel_ConnectionLost verifies that every forward moves to error when the shared connection drops.
+func TestMultiTunnel_ConnectionLost(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+
+	multi := NewMultiTunnel(cfg)
+	fwd, _ := multi.AddForward("127.0.0.1", 1521, 0)
+
+	if err := multi.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer multi.Close()
+
+	sshServer.Close()
+	waitForStatus(t, fwd, StatusError)
+
+	if multi.Status() != StatusError {
+		t.Errorf("expected status %s, got %s", StatusError, multi.Status())
+	}
+}
@@ -0,0 +1,172 @@
+package tunnel
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 protocol constants, as defined in RFC 1928.
+const (
+	socksVersion5 = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyGeneralFailure      = 0x01
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddrNotSupported    = 0x08
+)
+
+// socksHandshakeTimeout bounds how long a client may take to complete the SOCKS5 handshake.
+const socksHandshakeTimeout = 10 * time.Second
+
+// NewSocksTunnel initializes a Tunnel that acts as a local SOCKS5 proxy on localPort, dialing every requested
+// destination through the SSH connection (like ssh -D). A localPort of 0 picks a free port on Start.
+func NewSocksTunnel(config *SSHConfig, localPort int) *Tunnel {
+	t := NewTunnel(config, "", 0, localPort)
+	t.direction = DirectionDynamic
+	return t
+}
+
+// serveSocks performs the SOCKS5 handshake on conn, dials the requested destination through the SSH client and pipes
+// the two connections. It owns conn and the active connection counted for it in the run identified by done, described
+// by ev.
+func (t *Tunnel) serveSocks(conn net.Conn, ev ConnEvent, done chan struct{}) {
+	_ = conn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+
+	targetConn, err := t.socksConnect(conn)
+	if err != nil {
+		t.logger().Warn("socks request failed", "remote_addr", ev.RemoteAddr, "error", err)
+		t.dropConn(conn, ev, err, done)
+		return
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	t.pipe(conn, targetConn, ev, done)
+}
+
+// socksConnect negotiates a SOCKS5 CONNECT request on conn and returns the connection to the requested destination.
+// Only the "no authentication" method and the CONNECT command are supported.
+func (t *Tunnel) socksConnect(conn net.Conn) (net.Conn, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, fmt.Errorf("failed to read socks greeting: %w", err)
+	}
+
+	if header[0] != socksVersion5 {
+		return nil, fmt.Errorf("unsupported socks version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return nil, fmt.Errorf("failed to read socks methods: %w", err)
+	}
+
+	method := byte(socksMethodNoAcceptable)
+	for _, m := range methods {
+		if m == socksMethodNoAuth {
+			method = socksMethodNoAuth
+			break
+		}
+	}
+
+	if _, err := conn.Write([]byte{socksVersion5, method}); err != nil {
+		return nil, fmt.Errorf("failed to write socks method: %w", err)
+	}
+
+	if method == socksMethodNoAcceptable {
+		return nil, fmt.Errorf("no acceptable socks authentication method")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return nil, fmt.Errorf("failed to read socks request: %w", err)
+	}
+
+	if request[1] != socksCmdConnect {
+		_ = writeSocksReply(conn, socksReplyCommandNotSupported)
+		return nil, fmt.Errorf("unsupported socks command %d", request[1])
+	}
+
+	host, err := readSocksAddr(conn, request[3])
+	if err != nil {
+		_ = writeSocksReply(conn, socksReplyAddrNotSupported)
+		return nil, err
+	}
+
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBytes); err != nil {
+		return nil, fmt.Errorf("failed to read socks port: %w", err)
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+
+	t.mu.RLock()
+	client := t.client
+	t.mu.RUnlock()
+
+	if client == nil {
+		_ = writeSocksReply(conn, socksReplyGeneralFailure)
+		return nil, fmt.Errorf("ssh client is not connected")
+	}
+
+	targetConn, err := client.Dial("tcp", addr)
+	if err != nil {
+		_ = writeSocksReply(conn, socksReplyGeneralFailure)
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+
+	if err := writeSocksReply(conn, socksReplySucceeded); err != nil {
+		_ = targetConn.Close()
+		return nil, fmt.Errorf("failed to write socks reply: %w", err)
+	}
+
+	return targetConn, nil
+}
+
+// readSocksAddr reads a destination address of the given SOCKS5 address type from r.
+func readSocksAddr(r io.Reader, addrType byte) (string, error) {
+	switch addrType {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if addrType == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", fmt.Errorf("failed to read socks address: %w", err)
+		}
+		return net.IP(ip).String(), nil
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return "", fmt.Errorf("failed to read socks address: %w", err)
+		}
+
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(r, domain); err != nil {
+			return "", fmt.Errorf("failed to read socks address: %w", err)
+		}
+		return string(domain), nil
+	default:
+		return "", fmt.Errorf("unsupported socks address type %d", addrType)
+	}
+}
+
+// writeSocksReply sends a SOCKS5 reply with the given code and an unspecified bound address.
+func writeSocksReply(w io.Writer, code byte) error {
+	_, err := w.Write([]byte{socksVersion5, code, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
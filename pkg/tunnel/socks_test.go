@@ -0,0 +1,146 @@
+package tunnel
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// socksDial connects to the SOCKS5 proxy at proxyAddr and sends a CONNECT request for host:port, returning the
+// connection and the reply code sent by the proxy.
+func socksDial(t *testing.T, proxyAddr string, cmd byte, host string, port int) (net.Conn, byte) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		t.Fatalf("failed to connect to proxy: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if _, err := conn.Write([]byte{socksVersion5, 1, socksMethodNoAuth}); err != nil {
+		t.Fatalf("failed to write greeting: %v", err)
+	}
+
+	method := make([]byte, 2)
+	if _, err := io.ReadFull(conn, method); err != nil {
+		t.Fatalf("failed to read method: %v", err)
+	}
+	if method[1] != socksMethodNoAuth {
+		t.Fatalf("expected method %d, got %d", socksMethodNoAuth, method[1])
+	}
+
+	request := []byte{socksVersion5, cmd, 0x00, socksAddrDomain, byte(len(host))}
+	request = append(request, host...)
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+	if _, err := conn.Write(request); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	reply := make([]byte, 10)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("failed to read reply: %v", err)
+	}
+
+	return conn, reply[1]
+}
+
+// TestNewSocksTunnel_Validate verifies that a SOCKS tunnel does not require a remote host or port.
+func TestNewSocksTunnel_Validate(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	tun := NewSocksTunnel(cfg, 0)
+
+	if tun.Direction() != DirectionDynamic {
+		t.Errorf("expected direction %s, got %s", DirectionDynamic, tun.Direction())
+	}
+
+	if err := tun.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestSocksTunnel_Connect verifies that CONNECT requests are dialed through the SSH connection to the requested host.
+func TestSocksTunnel_Connect(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	oracle := setupTestDestinationServer(t, "hello from oracle")
+	defer oracle.Close()
+
+	app := setupTestDestinationServer(t, "hello from app")
+	defer app.Close()
+
+	tun := NewSocksTunnel(cfg, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	tests := map[net.Listener]string{
+		oracle: "hello from oracle",
+		app:    "hello from app",
+	}
+
+	for dest, want := range tests {
+		conn, code := socksDial(t, tun.LocalAddr(), socksCmdConnect, "127.0.0.1", dest.Addr().(*net.TCPAddr).Port)
+		if code != socksReplySucceeded {
+			conn.Close()
+			t.Fatalf("expected reply %d, got %d", socksReplySucceeded, code)
+		}
+
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		conn.Close()
+		if err != nil && err != io.EOF {
+			t.Fatalf("failed to read: %v", err)
+		}
+
+		if string(buf[:n]) != want {
+			t.Errorf("expected '%s', got '%s'", want, string(buf[:n]))
+		}
+	}
+
+	if tun.Stats().Connections != 2 {
+		t.Errorf("expected 2 connections, got %d", tun.Stats().Connections)
+	}
+}
+
+// TestSocksTunnel_UnsupportedCommand verifies that commands other than CONNECT are rejected.
+func TestSocksTunnel_UnsupportedCommand(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewSocksTunnel(cfg, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	// 0x02 is the SOCKS5 BIND command.
+	conn, code := socksDial(t, tun.LocalAddr(), 0x02, "127.0.0.1", 1521)
+	defer conn.Close()
+
+	if code != socksReplyCommandNotSupported {
+		t.Errorf("expected reply %d, got %d", socksReplyCommandNotSupported, code)
+	}
+}
+
+// TestSocksTunnel_DialFailure verifies that an unreachable destination is reported with a failure reply.
+func TestSocksTunnel_DialFailure(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewSocksTunnel(cfg, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, code := socksDial(t, tun.LocalAddr(), socksCmdConnect, "127.0.0.1", freeTestPort(t))
+	defer conn.Close()
+
+	if code != socksReplyGeneralFailure {
+		t.Errorf("expected reply %d, got %d", socksReplyGeneralFailure, code)
+	}
+}
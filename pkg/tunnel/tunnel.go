@@ -1,13 +1,21 @@
 package tunnel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 // Status defines a string-based enumeration for representing the operational state of a process or system.
@@ -20,22 +28,102 @@ const (
 	StatusError    Status = "error"
 )
 
+// Direction defines which side of the SSH connection listens for the connections being forwarded.
+type Direction string
+
+const (
+	// DirectionLocal listens on the local machine and forwards connections to the remote host through the SSH server.
+	DirectionLocal Direction = "local"
+
+	// DirectionReverse listens on the SSH server and forwards connections back to a port on the local machine.
+	DirectionReverse Direction = "reverse"
+
+	// DirectionDynamic listens on the local machine as a SOCKS5 proxy and dials each requested destination through
+	// the SSH server.
+	DirectionDynamic Direction = "dynamic"
+)
+
 // Stats represent statistical data related to network connections and activity over a specific period of time.
 type Stats struct {
-	BytesIn           int64
-	BytesOut          int64
-	Connections       int64
-	ActiveConnections int64
-	LastActivity      time.Time
-	StartedAt         time.Time
+	BytesIn             int64
+	BytesOut            int64
+	Connections         int64
+	ActiveConnections   int64
+	RejectedConnections int64
+	TimedOutConnections int64
+	Reconnects          int64
+	LastActivity        time.Time
+	StartedAt           time.Time
+}
+
+// Uptime returns how long the tunnel has been running, or zero if it has not started.
+func (s Stats) Uptime() time.Duration {
+	return s.uptime(time.Now())
+}
+
+// IdleTime returns how long the tunnel has gone without activity, counting from StartedAt when no connection
+// has been seen since, or zero if it has not started.
+func (s Stats) IdleTime() time.Duration {
+	return s.idleTime(time.Now())
 }
 
+func (s Stats) uptime(now time.Time) time.Duration {
+	if s.StartedAt.IsZero() {
+		return 0
+	}
+	return now.Sub(s.StartedAt)
+}
+
+func (s Stats) idleTime(now time.Time) time.Duration {
+	last := s.LastActivity
+	if last.Before(s.StartedAt) {
+		last = s.StartedAt
+	}
+
+	if last.IsZero() {
+		return 0
+	}
+	return now.Sub(last)
+}
+
+var (
+	// ErrIdleTimeout is recorded as the last error of a tunnel stopped because it was idle for longer than its idle timeout.
+	ErrIdleTimeout = errors.New("tunnel stopped after idle timeout")
+
+	// ErrNotRunning is returned by HealthCheck when the tunnel is not running.
+	ErrNotRunning = errors.New("tunnel is not running")
+
+	// ErrStopTimeout is returned by Stop when closing the listener and SSH client takes longer than the stop timeout.
+	ErrStopTimeout = errors.New("tunnel resources did not close before the stop timeout")
+
+	// ErrDialTimeout is returned by Start when the SSH server does not accept the TCP connection within the
+	// SSHConfig's DialTimeout.
+	ErrDialTimeout = errors.New("timed out connecting to the ssh server")
+)
+
+// keepaliveRequest is the global request sent by HealthCheck. Servers answer unknown requests with a failure reply,
+// which is enough to prove the connection is alive.
+const keepaliveRequest = "keepalive@openssh.com"
+
+// defaultBindAddress is the address local listeners bind to unless SetBindAddress is used.
+const defaultBindAddress = "127.0.0.1"
+
+// maxReconnectBackoff caps the exponential delay between reconnection attempts.
+const maxReconnectBackoff = time.Minute
+
+// minRestartInterval is the minimum delay UpdateConfigAndRestart keeps between two restarts.
+const minRestartInterval = time.Second
+
 // Tunnel represents a secure SSH-based port forwarding connection between a local and a remote host.
 type Tunnel struct {
-	config     *SSHConfig
-	remoteHost string
-	remotePort int
-	localPort  int
+	config      *SSHConfig
+	jumps       []*SSHConfig
+	chained     bool
+	remoteHost  string
+	remotePort  int
+	localPort   int
+	bindAddress string
+	direction   Direction
 
 	client     *ssh.Client
 	listener   net.Listener
@@ -45,52 +133,241 @@ type Tunnel struct {
 	lastError error
 	stats     Stats
 
+	// shared marks a tunnel whose SSH client belongs to a MultiTunnel and must not be closed by the tunnel itself.
+	shared bool
+
+	reconnect        bool
+	maxRetries       int
+	reconnectBackoff time.Duration
+	reconnectJitter  float64
+
+	idleTimeout     time.Duration
+	stopTimeout     time.Duration
+	connIdleTimeout time.Duration
+
+	maxConnections int
+	limitMode      LimitMode
+
+	// slotFreed is signaled, with mu held, when a connection slot frees or the current run ends.
+	slotFreed *sync.Cond
+
+	// onStarted is called each time Start succeeds, before Start returns.
+	onStarted func()
+
+	// log receives the tunnel events; it discards them unless SetLogger is used.
+	log *slog.Logger
+
+	// draining is set by StopGraceful while it waits for the active connections to finish.
+	draining bool
+
+	// conns holds both ends of every connection being piped, so a tunnel sharing a MultiTunnel's SSH client can close
+	// them on Stop without closing the client.
+	conns map[net.Conn]struct{}
+
+	// now returns the time used for statistics and connection events; tests replace it to get fixed timestamps.
+	now func() time.Time
+
+	// after returns a channel that fires once d has elapsed on the same clock as now; UpdateConfigAndRestart waits on
+	// it between restarts.
+	after func(d time.Duration) <-chan time.Time
+
+	// restartMu serializes UpdateConfigAndRestart; lastRestart and restartInterval space its restarts apart.
+	restartMu       sync.Mutex
+	lastRestart     time.Time
+	restartInterval time.Duration
+
+	onConnection func(ConnEvent)
+	onStats      func(Stats)
+	onReady      func(localPort int)
+
 	done chan struct{}
 	mu   sync.RWMutex
 }
 
 // NewTunnel initializes a Tunnel with the provided SSHConfig, remote host, remote port, and local port settings.
 func NewTunnel(config *SSHConfig, remoteHost string, remotePort, localPort int) *Tunnel {
-	return &Tunnel{
-		config:     config,
-		remoteHost: remoteHost,
-		remotePort: remotePort,
-		localPort:  localPort,
-		status:     StatusStopped,
+	t := &Tunnel{
+		config:      config,
+		remoteHost:  remoteHost,
+		remotePort:  remotePort,
+		localPort:   localPort,
+		bindAddress: defaultBindAddress,
+		direction:   DirectionLocal,
+		status:      StatusStopped,
+		log:         slog.New(slog.DiscardHandler),
+		now:         time.Now,
+		after:       time.After,
+
+		restartInterval: minRestartInterval,
 	}
+	t.slotFreed = sync.NewCond(&t.mu)
+	return t
+}
+
+// NewReverseTunnel initializes a Tunnel that asks the SSH server to listen on remoteHost:remotePort and forwards every
+// connection it receives back to the given local port.
+func NewReverseTunnel(config *SSHConfig, remoteHost string, remotePort, localPort int) *Tunnel {
+	t := NewTunnel(config, remoteHost, remotePort, localPort)
+	t.direction = DirectionReverse
+	return t
 }
 
 // Validate checks if the Tunnel's configuration and parameters are valid, returning an error if any validation fails.
 func (t *Tunnel) Validate() error {
+	if t.chained && t.config == nil {
+		return fmt.Errorf("at least one hop is required")
+	}
+
 	if t.config == nil {
 		return fmt.Errorf("config is required")
 	}
 
-	if t.remoteHost == "" {
-		return fmt.Errorf("remoteHost is required")
+	for i, jump := range t.jumps {
+		if jump == nil {
+			return fmt.Errorf("hop %d: config is required", i)
+		}
 	}
 
-	if t.remotePort <= 0 {
-		return fmt.Errorf("remotePort must be greater than 0")
+	if t.direction != DirectionDynamic {
+		if t.remoteHost == "" {
+			return fmt.Errorf("remoteHost is required")
+		}
+
+		if t.remotePort <= 0 {
+			return fmt.Errorf("remotePort must be greater than 0")
+		}
 	}
 
 	if t.localPort < 0 {
 		return fmt.Errorf("localPort must be 0 or greater")
 	}
 
+	if !isValidHost(t.bindAddress) {
+		return fmt.Errorf("bindAddress must be a valid IP address or hostname")
+	}
+
+	if t.direction == DirectionReverse && t.localPort == 0 {
+		return fmt.Errorf("localPort must be greater than 0 for a reverse tunnel")
+	}
+
 	return nil
 }
 
+// EnableReconnect turns on automatic reconnection: when the SSH connection drops, the tunnel keeps its local
+// listener open and re-dials the SSH server, waiting backoff before the first retry and doubling the delay on each
+// subsequent one. A maxRetries of 0 or less retries until the tunnel is stopped. It takes effect on the next Start.
+func (t *Tunnel) EnableReconnect(maxRetries int, backoff time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reconnect = true
+	t.maxRetries = maxRetries
+	t.reconnectBackoff = backoff
+}
+
+// DisableReconnect turns off automatic reconnection, so a dropped SSH connection leaves the tunnel in error.
+func (t *Tunnel) DisableReconnect() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reconnect = false
+}
+
+// SetReconnectJitter randomizes each reconnection delay of EnableReconnect by up to fraction of it, e.g. 0.5 spreads
+// a 2s delay over [1s, 3s], so tunnels that lose the same SSH server do not reconnect in lockstep. A fraction of 0,
+// the default, disables it; values above 1 are treated as 1.
+func (t *Tunnel) SetReconnectJitter(fraction float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reconnectJitter = min(max(fraction, 0), 1)
+}
+
+// SetBindAddress sets the address the local listener binds to, "127.0.0.1" by default. Use "0.0.0.0" to accept
+// connections from other hosts or containers. For reverse tunnels it is the address of the local target.
+// It takes effect on the next Start.
+func (t *Tunnel) SetBindAddress(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bindAddress = address
+}
+
+// SetIdleTimeout makes the tunnel stop itself once no connection has been active and no data has flowed for timeout.
+// The tunnel is then left stopped with ErrIdleTimeout as its last error. A timeout of 0 or less disables it.
+// It takes effect on the next Start.
+func (t *Tunnel) SetIdleTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.idleTimeout = timeout
+}
+
+// SetStopTimeout bounds how long Stop waits for the listener and SSH client to close. When it expires, Stop abandons
+// them, still leaves the tunnel stopped and returns an error wrapping ErrStopTimeout. A timeout of 0 or less waits
+// indefinitely, which is the default.
+func (t *Tunnel) SetStopTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopTimeout = timeout
+}
+
+// SetLogger sets the logger receiving lifecycle transitions at info level, recoverable failures such as accept and
+// dial errors at warn level, fatal errors at error level and individual connections at debug level.
+// A nil logger discards everything, which is the default.
+func (t *Tunnel) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.log = logger
+}
+
+// logger returns the tunnel logger in a thread-safe manner. Code already holding t.mu uses t.log directly.
+func (t *Tunnel) logger() *slog.Logger {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.log
+}
+
 // setError updates the tunnel's status to error and records the provided error as the last encountered error.
 func (t *Tunnel) setError(err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.status = StatusError
 	t.lastError = err
+	t.log.Error("tunnel failed to start", "error", err)
 }
 
 // Start initializes and starts the tunnel, setting up the SSH connection and local listener. Returns an error if it fails.
 func (t *Tunnel) Start() error {
+	return t.StartContext(context.Background())
+}
+
+// StartContext starts the tunnel like Start, aborting the SSH dial, the SSH handshake and the local listener setup
+// when ctx is canceled or its deadline expires.
+func (t *Tunnel) StartContext(ctx context.Context) error {
+	if err := t.begin(); err != nil {
+		return err
+	}
+
+	client, err := t.dial(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to connect to ssh server: %w", err)
+		t.setError(err)
+		return err
+	}
+
+	done, err := t.listen(ctx, client)
+	if err != nil {
+		_ = client.Close()
+		return err
+	}
+
+	go t.watch(client, done)
+
+	return nil
+}
+
+// begin moves the tunnel to the starting state, failing if it is already running or its parameters are invalid.
+func (t *Tunnel) begin() error {
 	t.mu.Lock()
 
 	if t.status == StatusRunning {
@@ -107,69 +384,272 @@ func (t *Tunnel) Start() error {
 		return err
 	}
 
-	sshClientConfig := &ssh.ClientConfig{
-		User:            t.config.User,
-		Auth:            t.config.AuthMethods,
-		HostKeyCallback: t.config.HostKeyCallback,
-		Config: ssh.Config{
-			KeyExchanges: []string{
-				"diffie-hellman-group-exchange-sha256",
-				"diffie-hellman-group14-sha256",
-				"diffie-hellman-group14-sha1",
-				"curve25519-sha256",
-				"curve25519-sha256@libssh.org",
-				"ecdh-sha2-nistp256",
-				"ecdh-sha2-nistp384",
-				"ecdh-sha2-nistp521",
-			},
-		},
-	}
-
-	client, err := ssh.Dial("tcp", t.config.Addr(), sshClientConfig)
-	if err != nil {
-		err = fmt.Errorf("failed to connect to ssh server: %w", err)
-		t.setError(err)
-		return err
-	}
+	return nil
+}
 
-	listenAddr := fmt.Sprintf("127.0.0.1:%d", t.localPort)
-	listener, err := net.Listen("tcp", listenAddr)
+// listen opens the listener, marks the tunnel as running over client and starts accepting connections.
+// It returns the channel closed when this run of the tunnel ends.
+func (t *Tunnel) listen(ctx context.Context, client *ssh.Client) (chan struct{}, error) {
+	listener, actualPort, err := t.openListener(ctx, client)
 	if err != nil {
-		_ = client.Close()
-		err = fmt.Errorf("failed to create local listener: %w", err)
 		t.setError(err)
-		return err
+		return nil, err
 	}
 
-	actualPort := listener.Addr().(*net.TCPAddr).Port
-
 	t.mu.Lock()
 	t.client = client
 	t.listener = listener
 	t.actualPort = actualPort
 	t.status = StatusRunning
 	t.done = make(chan struct{})
-	t.stats = Stats{StartedAt: time.Now()}
+	t.stats = Stats{StartedAt: t.now()}
+	done := t.done
+	idleTimeout := t.idleTimeout
+	onReady := t.onReady
 	t.mu.Unlock()
 
-	go t.forward()
+	if onReady != nil {
+		onReady(actualPort)
+	}
 
-	return nil
+	t.logger().Info("tunnel started", "direction", t.direction, "local_addr", t.LocalAddr(), "remote_addr", t.RemoteAddr())
+
+	if t.onStarted != nil {
+		t.onStarted()
+	}
+
+	go t.forward(listener, done)
+	go t.watchStats(done)
+
+	if idleTimeout > 0 {
+		go t.watchIdle(done, idleTimeout)
+	}
+
+	return done, nil
+}
+
+// openListener opens the listener matching the tunnel direction: a local TCP listener, or a listener on the SSH
+// server for reverse tunnels. It also returns the actual local port, which is 0 for reverse tunnels.
+func (t *Tunnel) openListener(ctx context.Context, client *ssh.Client) (net.Listener, int, error) {
+	if t.direction == DirectionReverse {
+		listener, err := client.Listen("tcp", t.RemoteAddr())
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create remote listener: %w", err)
+		}
+		return listener, 0, nil
+	}
+
+	t.mu.RLock()
+	listenAddr := net.JoinHostPort(t.bindAddress, strconv.Itoa(t.localPort))
+	t.mu.RUnlock()
+
+	var listenConfig net.ListenConfig
+	listener, err := listenConfig.Listen(ctx, "tcp", listenAddr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create local listener: %w", err)
+	}
+
+	return listener, listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// dialSSH opens the TCP connection to the SSH server and performs the SSH handshake, honoring ctx cancellation.
+func dialSSH(ctx context.Context, config *SSHConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: config.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", config.Addr())
+	if err != nil {
+		var netErr net.Error
+		if ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("%w after %s: %w", ErrDialTimeout, config.DialTimeout, err)
+		}
+		return nil, err
+	}
+
+	return handshakeSSH(ctx, conn, config)
+}
+
+// defaultKeyExchanges are the key exchange algorithms offered when SSHConfig.KeyExchanges is empty.
+var defaultKeyExchanges = []string{
+	"diffie-hellman-group-exchange-sha256",
+	"diffie-hellman-group14-sha256",
+	"diffie-hellman-group14-sha1",
+	"curve25519-sha256",
+	"curve25519-sha256@libssh.org",
+	"ecdh-sha2-nistp256",
+	"ecdh-sha2-nistp384",
+	"ecdh-sha2-nistp521",
+}
+
+// clientConfig returns the ssh.ClientConfig for config. Empty algorithm lists keep the defaults.
+func clientConfig(config *SSHConfig) *ssh.ClientConfig {
+	keyExchanges := config.KeyExchanges
+	if len(keyExchanges) == 0 {
+		keyExchanges = defaultKeyExchanges
+	}
+
+	return &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            config.AuthMethods,
+		HostKeyCallback: config.HostKeyCallback,
+		Config: ssh.Config{
+			KeyExchanges: keyExchanges,
+			Ciphers:      config.Ciphers,
+			MACs:         config.MACs,
+		},
+	}
+}
+
+// handshakeSSH performs the SSH handshake with config over an established conn, closing conn if it fails or ctx is done.
+func handshakeSSH(ctx context.Context, conn net.Conn, config *SSHConfig) (*ssh.Client, error) {
+	sshClientConfig := clientConfig(config)
+
+	if config.UseAgent {
+		agentConn, err := dialAgent()
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		// The agent only signs during authentication, so its connection is closed once the handshake returns.
+		defer agentConn.Close()
+
+		sshClientConfig.Auth = config.authMethods(agent.NewClient(agentConn))
+	}
+
+	// The SSH handshake is not context aware, so the connection is closed to unblock it on cancellation.
+	handshakeDone := make(chan struct{})
+	canceled := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+			canceled <- true
+		case <-handshakeDone:
+			canceled <- false
+		}
+	}()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, config.Addr(), sshClientConfig)
+	close(handshakeDone)
+
+	if <-canceled {
+		if err == nil {
+			_ = sshConn.Close()
+		}
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
 }
 
 // Stop terminates the tunnel by closing any active connections, freeing resources, and updating the tunnel's status.
 func (t *Tunnel) Stop() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	return t.stopLocked()
+}
 
+// stopLocked performs Stop with t.mu already held.
+func (t *Tunnel) stopLocked() error {
 	if t.status == StatusStopped {
 		return nil
 	}
 
 	if t.done != nil {
 		close(t.done)
+		t.done = nil
+		t.slotFreed.Broadcast()
+	}
+
+	listener, client := t.listener, t.client
+	if t.shared {
+		// The shared client keeps running, so the connections piped over it are closed one by one.
+		client = nil
+		for conn := range t.conns {
+			_ = conn.Close()
+		}
+		clear(t.conns)
+	}
+	t.listener = nil
+	t.client = nil
+
+	errs := closeResources(listener, client, t.stopTimeout)
+
+	t.status = StatusStopped
+	t.actualPort = 0
+	t.stats = Stats{}
+	t.draining = false
+	t.log.Info("tunnel stopped")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors stopping tunnel: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// closeResources closes listener and client, either of which may be nil. With a positive timeout the closes run in
+// the background and are abandoned with ErrStopTimeout if they have not finished in time.
+func closeResources(listener net.Listener, client *ssh.Client, timeout time.Duration) []error {
+	closeAll := func() []error {
+		var errs []error
+		if listener != nil {
+			if err := listener.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close listener: %w", err))
+			}
+		}
+
+		if client != nil {
+			if err := client.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close ssh client: %w", err))
+			}
+		}
+		return errs
+	}
+
+	if timeout <= 0 {
+		return closeAll()
+	}
+
+	result := make(chan []error, 1)
+	go func() {
+		result <- closeAll()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case errs := <-result:
+		return errs
+	case <-timer.C:
+		return []error{ErrStopTimeout}
+	}
+}
+
+// StopGraceful stops accepting new connections, waits for the active ones to finish and then stops the tunnel like
+// Stop. If ctx is done first, the remaining connections are closed and the returned error wraps ctx.Err().
+func (t *Tunnel) StopGraceful(ctx context.Context) error {
+	// Wake the wait below when ctx is done; the lock orders the broadcast after the wait has started.
+	stopWaking := context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.slotFreed.Broadcast()
+	})
+	defer stopWaking()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status == StatusStopped {
+		return nil
 	}
 
+	t.draining = true
+
 	var errs []error
 	if t.listener != nil {
 		if err := t.listener.Close(); err != nil {
@@ -178,22 +658,20 @@ func (t *Tunnel) Stop() error {
 		t.listener = nil
 	}
 
-	if t.client != nil {
-		if err := t.client.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to close ssh client: %w", err))
-		}
-		t.client = nil
+	done := t.done
+	for t.done == done && t.stats.ActiveConnections > 0 && ctx.Err() == nil {
+		t.slotFreed.Wait()
 	}
 
-	t.status = StatusStopped
-	t.actualPort = 0
-	t.stats = Stats{}
+	if t.stats.ActiveConnections > 0 && ctx.Err() != nil {
+		errs = append(errs, fmt.Errorf("drain interrupted with %d active connections: %w", t.stats.ActiveConnections, ctx.Err()))
+	}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("errors stopping tunnel: %v", errs)
+	if err := t.stopLocked(); err != nil {
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // Restart stops the tunnel if running and then starts it again, returning an error if either operation fails.
@@ -212,6 +690,67 @@ func (t *Tunnel) UpdateConfig(config *SSHConfig) {
 	t.config = config
 }
 
+// UpdateConfigAndRestart updates the tunnel's SSH configuration like UpdateConfig and, when the tunnel is running
+// and config connects differently from the current configuration, restarts it so the new configuration takes effect.
+// Concurrent calls are serialized and restarts are spaced at least one second apart, so rapid updates cannot cause a
+// restart storm.
+func (t *Tunnel) UpdateConfigAndRestart(config *SSHConfig) error {
+	return t.UpdateConfigAndRestartContext(context.Background(), config)
+}
+
+// UpdateConfigAndRestartContext updates the configuration like UpdateConfigAndRestart, giving up the wait between two
+// restarts when ctx is canceled or its deadline expires. In that case the new configuration is kept but the tunnel
+// is not restarted.
+func (t *Tunnel) UpdateConfigAndRestartContext(ctx context.Context, config *SSHConfig) error {
+	t.restartMu.Lock()
+	defer t.restartMu.Unlock()
+
+	t.mu.Lock()
+	old := t.config
+	t.config = config
+	running := t.status == StatusRunning
+	t.mu.Unlock()
+
+	if !running || sameConnection(old, config) {
+		return nil
+	}
+
+	if wait := t.restartInterval - t.now().Sub(t.lastRestart); wait > 0 {
+		select {
+		case <-t.after(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("restart not performed: %w", ctx.Err())
+		}
+	}
+
+	t.lastRestart = t.now()
+	t.logger().Info("restarting tunnel after config change", "ssh_host", config.Host, "ssh_user", config.User)
+
+	return t.Restart()
+}
+
+// sameConnection reports whether a and b connect to the same server with the same credentials and host key checks.
+func sameConnection(a, b *SSHConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.User == b.User &&
+		a.Password == b.Password &&
+		a.KeyFile == b.KeyFile &&
+		a.KeyPassphrase == b.KeyPassphrase &&
+		a.Host == b.Host &&
+		a.Port == b.Port &&
+		a.KnownHostsFile == b.KnownHostsFile &&
+		a.HostKeyFingerprint == b.HostKeyFingerprint &&
+		a.UseAgent == b.UseAgent &&
+		a.Insecure == b.Insecure &&
+		a.DialTimeout == b.DialTimeout &&
+		slices.Equal(a.Ciphers, b.Ciphers) &&
+		slices.Equal(a.MACs, b.MACs) &&
+		slices.Equal(a.KeyExchanges, b.KeyExchanges)
+}
+
 // Status returns the current operational state of the tunnel in a thread-safe manner.
 func (t *Tunnel) Status() Status {
 	t.mu.RLock()
@@ -236,9 +775,12 @@ func (t *Tunnel) LocalPort() int {
 	return t.localPort
 }
 
-// LocalAddr returns the local address and port as a string in the format "127.0.0.1:<port>".
+// LocalAddr returns the local address and port as a string in the format "<bindAddress>:<port>".
 func (t *Tunnel) LocalAddr() string {
-	return fmt.Sprintf("127.0.0.1:%d", t.LocalPort())
+	t.mu.RLock()
+	bindAddress := t.bindAddress
+	t.mu.RUnlock()
+	return net.JoinHostPort(bindAddress, strconv.Itoa(t.LocalPort()))
 }
 
 // RemoteAddr retorna o endereço remoto.
@@ -248,6 +790,42 @@ func (t *Tunnel) RemoteAddr() string {
 	return fmt.Sprintf("%s:%d", t.remoteHost, t.remotePort)
 }
 
+// Direction returns whether the tunnel forwards local connections to the remote host, the reverse, or acts as a
+// SOCKS5 proxy.
+func (t *Tunnel) Direction() Direction {
+	return t.direction
+}
+
+// HealthCheck verifies that the SSH connection can still carry traffic by sending a keepalive request and waiting
+// for the server's reply. It returns ErrNotRunning if the tunnel is not running, or an error if the connection is dead
+// or ctx is done before the reply arrives.
+func (t *Tunnel) HealthCheck(ctx context.Context) error {
+	t.mu.RLock()
+	status := t.status
+	client := t.client
+	t.mu.RUnlock()
+
+	if status != StatusRunning || client == nil {
+		return ErrNotRunning
+	}
+
+	replied := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest(keepaliveRequest, true, nil)
+		replied <- err
+	}()
+
+	select {
+	case err := <-replied:
+		if err != nil {
+			return fmt.Errorf("ssh connection is not alive: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Stats retrieves the statistical data related to network activity for the tunnel in a thread-safe manner.
 func (t *Tunnel) Stats() Stats {
 	t.mu.RLock()
@@ -261,84 +839,389 @@ func (t *Tunnel) Close() error {
 }
 
 // forward establishes and manages a connection between a local endpoint and a remote endpoint through the tunnel.
-func (t *Tunnel) forward() {
+func (t *Tunnel) forward(listener net.Listener, done chan struct{}) {
 	for {
 		select {
-		case <-t.done:
+		case <-done:
 			return
 		default:
 		}
 
-		localConn, err := t.listener.Accept()
+		if !t.waitForSlot(done) {
+			return
+		}
+
+		acceptedConn, err := listener.Accept()
 		if err != nil {
 			select {
-			case <-t.done:
+			case <-done:
 				return
 			default:
-				continue
 			}
+
+			// A listener on the SSH server is closed along with a dropped connection.
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+				return
+			}
+			t.logger().Warn("failed to accept connection", "error", err)
+			continue
 		}
 
-		t.mu.Lock()
-		t.stats.Connections++
-		t.stats.ActiveConnections++
-		t.mu.Unlock()
+		if !t.admitConn(acceptedConn) {
+			continue
+		}
 
-		t.mu.RLock()
-		remoteAddr := fmt.Sprintf("%s:%d", t.remoteHost, t.remotePort)
-		client := t.client
-		t.mu.RUnlock()
+		ev := ConnEvent{Type: ConnOpened, RemoteAddr: acceptedConn.RemoteAddr().String(), OpenedAt: t.now()}
+		t.logger().Debug("connection opened", "remote_addr", ev.RemoteAddr)
+		t.notifyConn(ev)
+
+		if t.direction == DirectionDynamic {
+			go t.serveSocks(acceptedConn, ev, done)
+			continue
+		}
 
-		remoteConn, err := client.Dial("tcp", remoteAddr)
+		targetConn, err := t.dialTarget()
 		if err != nil {
-			_ = localConn.Close()
+			t.logger().Warn("failed to dial target", "remote_addr", ev.RemoteAddr, "error", err)
+			t.dropConn(acceptedConn, ev, err, done)
+			continue
+		}
+
+		if t.direction == DirectionReverse {
+			go t.pipe(targetConn, acceptedConn, ev, done)
+		} else {
+			go t.pipe(acceptedConn, targetConn, ev, done)
+		}
+	}
+}
+
+// dialTarget opens the connection accepted connections are forwarded to: the remote address through the SSH
+// client, or the local port for reverse tunnels.
+func (t *Tunnel) dialTarget() (net.Conn, error) {
+	t.mu.RLock()
+	remoteAddr := fmt.Sprintf("%s:%d", t.remoteHost, t.remotePort)
+	localAddr := net.JoinHostPort(t.bindAddress, strconv.Itoa(t.localPort))
+	client := t.client
+	t.mu.RUnlock()
+
+	if t.direction == DirectionReverse {
+		return net.Dial("tcp", localAddr)
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("ssh client is not connected")
+	}
+
+	return client.Dial("tcp", remoteAddr)
+}
+
+// watch waits for the SSH connection to drop and, unless the tunnel was stopped, reconnects it or records the failure.
+func (t *Tunnel) watch(client *ssh.Client, done chan struct{}) {
+	err := client.Wait()
+
+	select {
+	case <-done:
+		return
+	default:
+	}
+
+	t.mu.RLock()
+	reconnect := t.reconnect
+	t.mu.RUnlock()
+
+	if !reconnect {
+		t.fail(done, fmt.Errorf("ssh connection lost: %w", err))
+		return
+	}
+
+	t.logger().Warn("ssh connection lost, reconnecting", "error", err)
+
+	t.reconnectLoop(done)
+}
+
+// reconnectLoop re-dials the SSH server with exponential backoff while the local listener stays open.
+func (t *Tunnel) reconnectLoop(done chan struct{}) {
+	t.mu.Lock()
+	if t.done != done {
+		t.mu.Unlock()
+		return
+	}
+	t.status = StatusStarting
+	maxRetries := t.maxRetries
+	backoff := t.reconnectBackoff
+	jitterFraction := t.reconnectJitter
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var lastErr error
+	for attempt := 1; maxRetries <= 0 || attempt <= maxRetries; attempt++ {
+		select {
+		case <-done:
+			return
+		case <-time.After(jitter(backoff, time.Duration(float64(backoff)*jitterFraction))):
+		}
+
+		client, err := t.dial(ctx)
+		if err == nil && t.direction == DirectionReverse {
+			err = t.relisten(client, done)
+		}
+
+		if err == nil {
 			t.mu.Lock()
-			t.stats.ActiveConnections--
+			if t.done != done {
+				t.mu.Unlock()
+				_ = client.Close()
+				return
+			}
+			t.client = client
+			t.status = StatusRunning
+			t.lastError = nil
+			t.stats.Reconnects++
+			t.log.Info("ssh connection restored", "attempt", attempt)
 			t.mu.Unlock()
-			continue
+
+			go t.watch(client, done)
+			return
 		}
 
-		go t.pipe(localConn, remoteConn)
+		lastErr = err
+		t.logger().Warn("reconnect attempt failed", "attempt", attempt, "error", err)
+		backoff = min(backoff*2, maxReconnectBackoff)
 	}
+
+	t.fail(done, fmt.Errorf("failed to reconnect after %d attempts: %w", maxRetries, lastErr))
 }
 
-// pipe establishes bidirectional data transfer between local and remote connections and manages connection lifecycle.
-func (t *Tunnel) pipe(local, remote net.Conn) {
-	defer func() {
-		_ = local.Close()
-		_ = remote.Close()
+// relisten replaces the listener of a reverse tunnel, which is lost along with the SSH connection, by a new one
+// opened over client.
+func (t *Tunnel) relisten(client *ssh.Client, done chan struct{}) error {
+	listener, err := client.Listen("tcp", t.RemoteAddr())
+	if err != nil {
+		_ = client.Close()
+		return fmt.Errorf("failed to create remote listener: %w", err)
+	}
+
+	t.mu.Lock()
+	t.listener = listener
+	t.mu.Unlock()
+
+	go t.forward(listener, done)
+
+	return nil
+}
+
+// watchIdle stops the run identified by done once it has had no active connection and no activity for timeout.
+func (t *Tunnel) watchIdle(done chan struct{}, timeout time.Duration) {
+	ticker := time.NewTicker(max(timeout/4, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
 		t.mu.Lock()
-		t.stats.ActiveConnections--
+		if t.done != done {
+			t.mu.Unlock()
+			return
+		}
+
+		if t.status != StatusRunning || t.stats.ActiveConnections > 0 || t.stats.idleTime(t.now()) < timeout {
+			t.mu.Unlock()
+			continue
+		}
+
+		t.log.Info("stopping idle tunnel", "idle_timeout", timeout)
+		_ = t.stopLocked()
+		t.lastError = ErrIdleTimeout
 		t.mu.Unlock()
-	}()
+		return
+	}
+}
+
+// fail records a fatal error for the run identified by done and releases its listener and SSH client.
+func (t *Tunnel) fail(done chan struct{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if done == nil || t.done != done {
+		return
+	}
+
+	close(t.done)
+	t.done = nil
+	t.slotFreed.Broadcast()
+
+	if t.listener != nil {
+		_ = t.listener.Close()
+		t.listener = nil
+	}
+
+	if t.client != nil && !t.shared {
+		_ = t.client.Close()
+	}
+	t.client = nil
+
+	t.status = StatusError
+	t.lastError = err
+	t.log.Error("tunnel failed", "error", err)
+}
+
+// pipe establishes bidirectional data transfer between local and remote connections and manages connection lifecycle.
+// Once both directions are done it reports the connection described by ev as closed and releases its slot in the run
+// identified by done. Copy errors only concern this connection, so they are reported in its ConnEvent and never
+// recorded as the tunnel's last error.
+func (t *Tunnel) pipe(local, remote net.Conn, ev ConnEvent, done chan struct{}) {
+	type copyResult struct {
+		n   int64
+		err error
+	}
 
-	done := make(chan struct{}, 2)
+	t.trackConns(local, remote)
+	defer t.untrackConns(local, remote)
+
+	out := make(chan copyResult, 1)
+	in := make(chan copyResult, 1)
+
+	t.mu.RLock()
+	idleTimeout := t.connIdleTimeout
+	t.mu.RUnlock()
+
+	// Reads are only wrapped when a connection idle timeout is set, keeping io.Copy's fast paths otherwise.
+	var localSrc, remoteSrc io.Reader = local, remote
+	var timedOut atomic.Bool
+	idleDone := make(chan struct{})
+	if idleTimeout > 0 {
+		last := new(atomic.Int64)
+		last.Store(t.now().UnixNano())
+		localSrc = &activityReader{r: local, now: t.now, last: last}
+		remoteSrc = &activityReader{r: remote, now: t.now, last: last}
+		go t.watchConnIdle(local, remote, last, idleTimeout, idleDone, &timedOut)
+	}
 
 	// Local -> Remote
 	go func() {
-		n, err := io.Copy(remote, local)
+		n, err := io.Copy(remote, localSrc)
 		t.mu.Lock()
 		t.stats.BytesOut += n
-		t.stats.LastActivity = time.Now()
-		if err != nil {
-			t.lastError = fmt.Errorf("local->remote copy failed: %w", err)
-		}
+		t.stats.LastActivity = t.now()
 		t.mu.Unlock()
-		done <- struct{}{}
+		out <- copyResult{n, copyError("local->remote", err)}
 	}()
 
 	// Remote -> Local
 	go func() {
-		n, err := io.Copy(local, remote)
+		n, err := io.Copy(local, remoteSrc)
 		t.mu.Lock()
 		t.stats.BytesIn += n
-		t.stats.LastActivity = time.Now()
-		if err != nil {
-			t.lastError = fmt.Errorf("remote->local copy failed: %w", err)
-		}
+		t.stats.LastActivity = t.now()
 		t.mu.Unlock()
-		done <- struct{}{}
+		in <- copyResult{n, copyError("remote->local", err)}
 	}()
 
-	<-done
+	// The first direction to finish ends the connection; closing both sides unblocks the other one.
+	var outResult, inResult copyResult
+	select {
+	case outResult = <-out:
+		ev.Err = outResult.err
+		_ = local.Close()
+		_ = remote.Close()
+		inResult = <-in
+	case inResult = <-in:
+		ev.Err = inResult.err
+		_ = local.Close()
+		_ = remote.Close()
+		outResult = <-out
+	}
+	close(idleDone)
+
+	if timedOut.Load() {
+		ev.Err = ErrConnIdleTimeout
+		t.mu.Lock()
+		t.stats.TimedOutConnections++
+		t.mu.Unlock()
+	}
+
+	t.releaseConn(done)
+
+	ev.Type = ConnClosed
+	ev.BytesOut = outResult.n
+	ev.BytesIn = inResult.n
+	ev.Duration = t.now().Sub(ev.OpenedAt)
+	t.logger().Debug("connection closed", "remote_addr", ev.RemoteAddr, "bytes_in", ev.BytesIn,
+		"bytes_out", ev.BytesOut, "duration", ev.Duration, "error", ev.Err)
+	t.notifyConn(ev)
+}
+
+// trackConns records the ends of a piped connection so stopLocked can close them. If the tunnel is already stopped
+// they are closed right away.
+func (t *Tunnel) trackConns(local, remote net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.status == StatusStopped {
+		_ = local.Close()
+		_ = remote.Close()
+		return
+	}
+
+	if t.conns == nil {
+		t.conns = make(map[net.Conn]struct{})
+	}
+	t.conns[local] = struct{}{}
+	t.conns[remote] = struct{}{}
+}
+
+// untrackConns forgets the ends of a piped connection once it is done.
+func (t *Tunnel) untrackConns(local, remote net.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.conns, local)
+	delete(t.conns, remote)
+}
+
+// copyError wraps a copy failure in the given direction, returning nil for a connection that was closed normally.
+func copyError(direction string, err error) error {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return fmt.Errorf("%s copy failed: %w", direction, err)
+}
+
+// isValidHost reports whether host is an IP address or a syntactically valid hostname.
+func isValidHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+
+	if host == "" || len(host) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+
+	return true
 }
@@ -1,11 +1,20 @@
 package tunnel
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,7 +23,7 @@ import (
 
 // TestNewTunnel verifies the creation of a new Tunnel instance and its initial state, ensuring proper configuration and status.
 func TestNewTunnel(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
@@ -29,7 +38,7 @@ func TestNewTunnel(t *testing.T) {
 
 // TestNewTunnel_DoesNotConnect verifies that a newly created tunnel is not connected and remains in the 'stopped' status.
 func TestNewTunnel_DoesNotConnect(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
@@ -44,7 +53,7 @@ func TestNewTunnel_DoesNotConnect(t *testing.T) {
 
 // TestValidate_Success verifies that a tunnel with valid configuration passes the validation without errors.
 func TestValidate_Success(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
 	err := tun.Validate()
@@ -70,7 +79,7 @@ func TestValidate_NilConfig(t *testing.T) {
 
 // TestValidate_EmptyRemoteHost verifies that the validation fails when the remoteHost is empty, returning the expected error.
 func TestValidate_EmptyRemoteHost(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "", 1521, 0)
 
 	err := tun.Validate()
@@ -86,7 +95,7 @@ func TestValidate_EmptyRemoteHost(t *testing.T) {
 
 // TestValidate_InvalidRemotePort tests the validation of invalid remotePort values in Tunnel configuration.
 func TestValidate_InvalidRemotePort(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 
 	tests := []struct {
 		name string
@@ -110,7 +119,7 @@ func TestValidate_InvalidRemotePort(t *testing.T) {
 
 // TestValidate_InvalidLocalPort verifies that Tunnel.Validate() returns an error when the localPort is set to a negative value.
 func TestValidate_InvalidLocalPort(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "remote-host", 1521, -1)
 
 	err := tun.Validate()
@@ -119,6 +128,17 @@ func TestValidate_InvalidLocalPort(t *testing.T) {
 	}
 }
 
+// TestValidate_ReverseRequiresLocalPort verifies that a reverse tunnel without a local target port is rejected.
+func TestValidate_ReverseRequiresLocalPort(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	tun := NewReverseTunnel(cfg, "127.0.0.1", 9000, 0)
+
+	err := tun.Validate()
+	if err == nil {
+		t.Fatal("expected error for reverse tunnel without localPort")
+	}
+}
+
 // TestStart_Success verifies that the tunnel starts successfully, achieves a running status, and assigns a valid port.
 func TestStart_Success(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
@@ -181,7 +201,7 @@ func TestStart_ValidationError(t *testing.T) {
 // TestStart_SSHConnectionFailed verifies that the tunnel's Start method handles a failed SSH connection gracefully.
 // It checks if an error is returned and ensures the tunnel's status is set to StatusError.
 func TestStart_SSHConnectionFailed(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 59999)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 59999)
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
 	err := tun.Start()
@@ -194,6 +214,72 @@ func TestStart_SSHConnectionFailed(t *testing.T) {
 	}
 }
 
+// TestStartContext_Success verifies that StartContext starts the tunnel when the context is not canceled.
+func TestStartContext_Success(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tun.StartContext(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if tun.Status() != StatusRunning {
+		t.Errorf("expected status %s, got %s", StatusRunning, tun.Status())
+	}
+}
+
+// TestStartContext_HandshakeTimeout verifies that StartContext aborts a hung SSH handshake when the deadline expires.
+func TestStartContext_HandshakeTimeout(t *testing.T) {
+	// Servidor que aceita a conexão TCP mas nunca responde o handshake SSH
+	hungServer := setupTestDestinationServerFunc(t, func(conn net.Conn) {
+		time.Sleep(5 * time.Second)
+		conn.Close()
+	})
+	defer hungServer.Close()
+
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "127.0.0.1", hungServer.Addr().(*net.TCPAddr).Port)
+	tun := NewTunnel(cfg, "remote-host", 1521, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := tun.StartContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected StartContext to return promptly, took %v", elapsed)
+	}
+
+	if tun.Status() != StatusError {
+		t.Errorf("expected status %s, got %s", StatusError, tun.Status())
+	}
+}
+
+// TestStartContext_Canceled verifies that StartContext fails immediately with an already canceled context.
+func TestStartContext_Canceled(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := tun.StartContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
 // TestStart_FixedLocalPort verifies that the tunnel starts successfully with a fixed local port and matches the expected port.
 func TestStart_FixedLocalPort(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
@@ -219,6 +305,64 @@ func TestStart_FixedLocalPort(t *testing.T) {
 	}
 }
 
+// TestValidate_InvalidBindAddress verifies that Tunnel.Validate() rejects bind addresses that are neither IPs nor hostnames.
+func TestValidate_InvalidBindAddress(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{"ipv4", "0.0.0.0", false},
+		{"ipv6", "::1", false},
+		{"hostname", "localhost", false},
+		{"fqdn", "tunnel.internal.example.com", false},
+		{"empty", "", true},
+		{"with port", "127.0.0.1:5432", true},
+		{"invalid characters", "bad host!", true},
+		{"leading hyphen", "-host", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tun := NewTunnel(cfg, "remote-host", 1521, 0)
+			tun.SetBindAddress(tt.address)
+
+			err := tun.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestStart_BindAddress verifies that the local listener binds to the configured address and LocalAddr reflects it.
+func TestStart_BindAddress(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello from oracle")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetBindAddress("0.0.0.0")
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	expected := fmt.Sprintf("0.0.0.0:%d", tun.LocalPort())
+	if tun.LocalAddr() != expected {
+		t.Errorf("expected local addr %s, got %s", expected, tun.LocalAddr())
+	}
+
+	if got := readFromTunnel(t, fmt.Sprintf("127.0.0.1:%d", tun.LocalPort())); got != "hello from oracle" {
+		t.Errorf("expected 'hello from oracle', got '%s'", got)
+	}
+}
+
 // TestStop_Success verifies that the Tunnel's Stop method successfully terminates the connection and updates the status.
 func TestStop_Success(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
@@ -243,7 +387,7 @@ func TestStop_Success(t *testing.T) {
 
 // TestStop_AlreadyStopped verifies that calling Stop on a tunnel that hasn't been started does not return an error.
 func TestStop_AlreadyStopped(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
 	err := tun.Stop()
@@ -279,6 +423,146 @@ func TestStop_ReleasesPort(t *testing.T) {
 	}
 }
 
+// TestStopGraceful_WaitsForActiveConnection verifies that StopGraceful refuses new connections but lets an in-flight
+// transfer finish before stopping.
+func TestStopGraceful_WaitsForActiveConnection(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupHeldDestinationServer(t, release)
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	localAddr := tun.LocalAddr()
+	conn, err := net.Dial("tcp", localAddr)
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+	waitForActiveConnections(t, tun, 1)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- tun.StopGraceful(context.Background()) }()
+
+	time.Sleep(200 * time.Millisecond)
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("expected StopGraceful to wait for the active connection, returned %v", err)
+	default:
+	}
+
+	if _, err := net.Dial("tcp", localAddr); err == nil {
+		t.Error("expected new connections to be refused while draining")
+	}
+
+	close(release)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(buf[:n]) != "done" {
+		t.Errorf("expected 'done', got '%s'", string(buf[:n]))
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("StopGraceful did not return after the connection finished")
+	}
+
+	if tun.Status() != StatusStopped {
+		t.Errorf("expected status %s, got %s", StatusStopped, tun.Status())
+	}
+}
+
+// TestStopGraceful_DeadlineForcesClose verifies that connections still active when ctx expires are closed, including
+// those of a forward whose SSH client is shared through a MultiTunnel.
+func TestStopGraceful_DeadlineForcesClose(t *testing.T) {
+	tests := []struct {
+		name  string
+		start func(t *testing.T, cfg *SSHConfig, destPort int) (*Tunnel, io.Closer)
+	}{
+		{
+			name: "tunnel",
+			start: func(t *testing.T, cfg *SSHConfig, destPort int) (*Tunnel, io.Closer) {
+				tun := NewTunnel(cfg, "127.0.0.1", destPort, 0)
+				if err := tun.Start(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return tun, tun
+			},
+		},
+		{
+			name: "multi tunnel forward",
+			start: func(t *testing.T, cfg *SSHConfig, destPort int) (*Tunnel, io.Closer) {
+				multi := NewMultiTunnel(cfg)
+				fwd, err := multi.AddForward("127.0.0.1", destPort, 0)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := multi.Start(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return fwd, multi
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sshServer, cfg := setupTestSSHServer(t)
+			defer sshServer.Close()
+
+			release := make(chan struct{})
+			destServer := setupHeldDestinationServer(t, release)
+			defer destServer.Close()
+			defer close(release)
+
+			tun, closer := tt.start(t, cfg, destServer.Addr().(*net.TCPAddr).Port)
+			defer closer.Close()
+
+			conn, err := net.Dial("tcp", tun.LocalAddr())
+			if err != nil {
+				t.Fatalf("failed to connect to tunnel: %v", err)
+			}
+			defer conn.Close()
+			waitForActiveConnections(t, tun, 1)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			err = tun.StopGraceful(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected context.DeadlineExceeded, got %v", err)
+			}
+
+			if tun.Status() != StatusStopped {
+				t.Errorf("expected status %s, got %s", StatusStopped, tun.Status())
+			}
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+				t.Errorf("expected the active connection to be closed, got %v", err)
+			}
+		})
+	}
+}
+
 // TestRestart_Success verifies that a tunnel can successfully restart and maintains the expected StatusRunning state afterward.
 func TestRestart_Success(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
@@ -323,8 +607,8 @@ func TestRestart_FromStopped(t *testing.T) {
 
 // TestUpdateConfig verifies the behavior of the Tunnel's UpdateConfig method by ensuring the SSH configuration is updated correctly.
 func TestUpdateConfig(t *testing.T) {
-	cfg1, _ := NewSSHConfig("user1", "pass1", "", "host1", "", 22)
-	cfg2, _ := NewSSHConfig("user2", "pass2", "", "host2", "", 22)
+	cfg1, _ := NewInsecureSSHConfig("user1", "pass1", "", "host1", 22)
+	cfg2, _ := NewInsecureSSHConfig("user2", "pass2", "", "host2", 22)
 
 	tun := NewTunnel(cfg1, "remote-host", 1521, 0)
 
@@ -339,6 +623,186 @@ func TestUpdateConfig(t *testing.T) {
 	}
 }
 
+// TestUpdateConfigAndRestart_ReconnectsWithNewUser verifies that changing the SSH user of a running tunnel restarts it
+// with the new credentials.
+func TestUpdateConfigAndRestart_ReconnectsWithNewUser(t *testing.T) {
+	users := make(chan string, 4)
+	listener := startTestSSHServer(t, &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			users <- c.User()
+			return nil, nil
+		},
+	})
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	cfg1, _ := NewInsecureSSHConfig("user1", "pass1", "", "127.0.0.1", port)
+	cfg2, _ := NewInsecureSSHConfig("user2", "pass2", "", "127.0.0.1", port)
+
+	tun := NewTunnel(cfg1, "127.0.0.1", 5432, 0)
+	tun.restartInterval = 0
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if got := <-users; got != "user1" {
+		t.Fatalf("expected first login as user1, got %s", got)
+	}
+
+	if err := tun.UpdateConfigAndRestart(cfg2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case got := <-users:
+		if got != "user2" {
+			t.Errorf("expected reconnect as user2, got %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel did not reconnect after config change")
+	}
+
+	if tun.Status() != StatusRunning {
+		t.Errorf("expected status running, got %s", tun.Status())
+	}
+}
+
+// TestUpdateConfigAndRestart_SameConnectionDoesNotRestart verifies that an update that does not change how the tunnel
+// connects keeps the current SSH connection.
+func TestUpdateConfigAndRestart_SameConnectionDoesNotRestart(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	tun.mu.RLock()
+	client := tun.client
+	tun.mu.RUnlock()
+
+	same := *cfg
+	if err := tun.UpdateConfigAndRestart(&same); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tun.mu.RLock()
+	defer tun.mu.RUnlock()
+	if tun.client != client {
+		t.Error("expected the SSH client to be kept")
+	}
+}
+
+// TestUpdateConfigAndRestart_Stopped verifies that a stopped tunnel only stores the new config.
+func TestUpdateConfigAndRestart_Stopped(t *testing.T) {
+	cfg1, _ := NewInsecureSSHConfig("user1", "pass1", "", "host1", 22)
+	cfg2, _ := NewInsecureSSHConfig("user2", "pass2", "", "host2", 22)
+
+	tun := NewTunnel(cfg1, "remote-host", 1521, 0)
+	if err := tun.UpdateConfigAndRestart(cfg2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tun.Status() != StatusStopped {
+		t.Errorf("expected status stopped, got %s", tun.Status())
+	}
+
+	if tun.config.User != "user2" {
+		t.Errorf("expected user 'user2', got '%s'", tun.config.User)
+	}
+}
+
+// TestUpdateConfigAndRestart_SpacesRestarts verifies that rapid updates are not restarted closer together than the
+// restart interval, measured on the tunnel clock.
+func TestUpdateConfigAndRestart_SpacesRestarts(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	clock := newFakeClock()
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+	tun.now = clock.Now
+	tun.after = clock.After
+	tun.restartInterval = time.Minute
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	update := func(i int) chan error {
+		next := *cfg
+		next.KeyPassphrase = fmt.Sprintf("unused-%d", i)
+		done := make(chan error, 1)
+		go func() { done <- tun.UpdateConfigAndRestart(&next) }()
+		return done
+	}
+
+	// The first restart runs immediately.
+	if err := <-update(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := update(1)
+	clock.Advance(30 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("expected the second restart to wait for the interval, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the second restart once the interval elapsed")
+	}
+}
+
+// TestUpdateConfigAndRestartContext_CanceledWait verifies that canceling ctx during the wait between restarts keeps
+// the new config without restarting the tunnel.
+func TestUpdateConfigAndRestartContext_CanceledWait(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	clock := newFakeClock()
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+	tun.now = clock.Now
+	tun.after = clock.After
+	tun.restartInterval = time.Minute
+	tun.lastRestart = clock.Now()
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+	port := tun.LocalPort()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	next := *cfg
+	next.KeyPassphrase = "unused"
+	err := tun.UpdateConfigAndRestartContext(ctx, &next)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	tun.mu.RLock()
+	kept := tun.config == &next
+	tun.mu.RUnlock()
+	if !kept {
+		t.Error("expected the new config to be kept")
+	}
+
+	if tun.Status() != StatusRunning || tun.LocalPort() != port {
+		t.Errorf("expected the tunnel to keep running on port %d, got %s on %d", port, tun.Status(), tun.LocalPort())
+	}
+}
+
 // TestLocalAddr verifies that the LocalAddr method of the Tunnel returns the correct formatted local address and port.
 func TestLocalAddr(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
@@ -360,7 +824,7 @@ func TestLocalAddr(t *testing.T) {
 
 // TestRemoteAddr verifies that the Tunnel's RemoteAddr method returns the expected remote address in the correct format.
 func TestRemoteAddr(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "oracle-server", 1521, 0)
 
 	expected := "oracle-server:1521"
@@ -371,7 +835,7 @@ func TestRemoteAddr(t *testing.T) {
 
 // TestLastError_NilWhenNoError ensures that calling LastError on a tunnel returns nil when no error has occurred.
 func TestLastError_NilWhenNoError(t *testing.T) {
-	cfg, _ := NewSSHConfig("user", "pass", "", "localhost", "", 22)
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
 	tun := NewTunnel(cfg, "remote-host", 1521, 0)
 
 	if tun.LastError() != nil {
@@ -416,51 +880,632 @@ func TestForwardData(t *testing.T) {
 	}
 }
 
-// TestMultipleConnections verifies if multiple sequential connections to the tunnel are handled correctly without errors.
-func TestMultipleConnections(t *testing.T) {
+// TestForwardData_CleanTransferLeavesNoError verifies that connections closed normally by either side are not
+// recorded as tunnel errors.
+func TestForwardData_CleanTransferLeavesNoError(t *testing.T) {
 	sshServer, cfg := setupTestSSHServer(t)
 	defer sshServer.Close()
 
-	connCount := 0
-	destServer := setupTestDestinationServerFunc(t, func(conn net.Conn) {
-		connCount++
-		fmt.Fprintf(conn, "connection %d", connCount)
-		conn.Close()
-	})
+	destServer := setupTestDestinationServer(t, "hello from oracle")
 	defer destServer.Close()
 
-	destPort := destServer.Addr().(*net.TCPAddr).Port
-
-	tun := NewTunnel(cfg, "127.0.0.1", destPort, 0)
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
 
-	err := tun.Start()
-	if err != nil {
+	if err := tun.Start(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	defer tun.Close()
 
-	for i := 1; i <= 3; i++ {
-		conn, err := net.Dial("tcp", tun.LocalAddr())
-		if err != nil {
-			t.Fatalf("failed to connect: %v", err)
+	for i := 0; i < 3; i++ {
+		if got := readFromTunnel(t, tun.LocalAddr()); got != "hello from oracle" {
+			t.Fatalf("expected 'hello from oracle', got '%s'", got)
 		}
+	}
 
-		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
-		buf := make([]byte, 1024)
-		n, _ := conn.Read(buf)
-		conn.Close()
+	// The client side closing first is a normal close as well.
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	conn.Close()
 
-		expected := fmt.Sprintf("connection %d", i)
-		if string(buf[:n]) != expected {
-			t.Errorf("expected '%s', got '%s'", expected, string(buf[:n]))
-		}
+	deadline := time.Now().Add(2 * time.Second)
+	for tun.Stats().ActiveConnections > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
 	}
-}
 
-// setupTestSSHServer creates and starts an SSH server for testing purposes and returns the listener and SSH config.
+	if tun.LastError() != nil {
+		t.Errorf("expected nil error, got %v", tun.LastError())
+	}
+}
+
+// TestCopyError verifies that normal connection closes are not reported as copy errors.
+func TestCopyError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"eof", io.EOF, false},
+		{"closed", fmt.Errorf("read: %w", net.ErrClosed), false},
+		{"other", errors.New("connection reset by peer"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := copyError("local->remote", tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestMultipleConnections verifies if multiple sequential connections to the tunnel are handled correctly without errors.
+func TestMultipleConnections(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	connCount := 0
+	destServer := setupTestDestinationServerFunc(t, func(conn net.Conn) {
+		connCount++
+		fmt.Fprintf(conn, "connection %d", connCount)
+		conn.Close()
+	})
+	defer destServer.Close()
+
+	destPort := destServer.Addr().(*net.TCPAddr).Port
+
+	tun := NewTunnel(cfg, "127.0.0.1", destPort, 0)
+
+	err := tun.Start()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	for i := 1; i <= 3; i++ {
+		conn, err := net.Dial("tcp", tun.LocalAddr())
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		buf := make([]byte, 1024)
+		n, _ := conn.Read(buf)
+		conn.Close()
+
+		expected := fmt.Sprintf("connection %d", i)
+		if string(buf[:n]) != expected {
+			t.Errorf("expected '%s', got '%s'", expected, string(buf[:n]))
+		}
+	}
+}
+
+// TestForwardData_WithAgent verifies that a tunnel authenticated through ssh-agent forwards data to the destination.
+func TestForwardData_WithAgent(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate private key: %v", err)
+	}
+
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to create public key: %v", err)
+	}
+
+	agentConns := setupTestAgent(t, privateKey)
+
+	sshServer := startTestSSHServer(t, &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == "testuser" && bytes.Equal(key.Marshal(), publicKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key")
+		},
+	})
+	defer sshServer.Close()
+
+	cfg := &SSHConfig{User: "testuser", Host: "127.0.0.1", Port: sshServer.Addr().(*net.TCPAddr).Port, UseAgent: true, Insecure: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("failed to create ssh config: %v", err)
+	}
+
+	destServer := setupTestDestinationServer(t, "hello from agent")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(buf[:n]) != "hello from agent" {
+		t.Errorf("expected 'hello from agent', got '%s'", string(buf[:n]))
+	}
+
+	// The agent is connected for the handshake only, and connected again for the next one.
+	waitForAgentConns(t, agentConns, 0)
+
+	if err := tun.Restart(); err != nil {
+		t.Fatalf("unexpected error on restart: %v", err)
+	}
+
+	waitForAgentConns(t, agentConns, 0)
+}
+
+// waitForAgentConns waits up to 2 seconds for the number of open agent connections to reach want.
+func waitForAgentConns(t *testing.T, conns *atomic.Int32, want int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for conns.Load() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d open ssh-agent connections, got %d", want, conns.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestReconnect_RecoversAfterServerRestart verifies that a tunnel with reconnection enabled re-dials the SSH server
+// after it goes down and resumes forwarding once it comes back.
+func TestReconnect_RecoversAfterServerRestart(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	sshAddr := sshServer.Addr().String()
+
+	destServer := setupTestDestinationServer(t, "hello again")
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.EnableReconnect(0, 50*time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	localAddr := tun.LocalAddr()
+
+	sshServer.Close()
+	waitForStatus(t, tun, StatusStarting)
+
+	time.Sleep(200 * time.Millisecond)
+	restarted := startTestSSHServerOn(t, sshAddr, newTestPasswordServerConfig())
+	defer restarted.Close()
+
+	waitForStatus(t, tun, StatusRunning)
+
+	if tun.LocalAddr() != localAddr {
+		t.Errorf("expected local address %s to be kept, got %s", localAddr, tun.LocalAddr())
+	}
+
+	if tun.Stats().Reconnects != 1 {
+		t.Errorf("expected 1 reconnect, got %d", tun.Stats().Reconnects)
+	}
+
+	conn, err := net.Dial("tcp", localAddr)
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(buf[:n]) != "hello again" {
+		t.Errorf("expected 'hello again', got '%s'", string(buf[:n]))
+	}
+}
+
+// TestReconnect_GivesUpAfterMaxRetries verifies that the tunnel ends in error once the retries are exhausted.
+func TestReconnect_GivesUpAfterMaxRetries(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.EnableReconnect(2, 10*time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	sshServer.Close()
+	waitForStatus(t, tun, StatusError)
+
+	if tun.LastError() == nil {
+		t.Error("expected last error to be set")
+	}
+}
+
+// TestConnectionLost_WithoutReconnect verifies that a dropped SSH connection puts the tunnel in error.
+func TestConnectionLost_WithoutReconnect(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	sshServer.Close()
+	waitForStatus(t, tun, StatusError)
+}
+
+// TestReverseForwardData verifies that connections accepted by the SSH server are forwarded back to the local port.
+func TestReverseForwardData(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	localServer := setupTestDestinationServer(t, "hello from webhook")
+	defer localServer.Close()
+
+	remotePort := freeTestPort(t)
+	tun := NewReverseTunnel(cfg, "127.0.0.1", remotePort, localServer.Addr().(*net.TCPAddr).Port)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if tun.Direction() != DirectionReverse {
+		t.Errorf("expected direction %s, got %s", DirectionReverse, tun.Direction())
+	}
+
+	if tun.Status() != StatusRunning {
+		t.Errorf("expected status %s, got %s", StatusRunning, tun.Status())
+	}
+
+	conn, err := net.Dial("tcp", tun.RemoteAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to remote listener: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil && err != io.EOF {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	if string(buf[:n]) != "hello from webhook" {
+		t.Errorf("expected 'hello from webhook', got '%s'", string(buf[:n]))
+	}
+
+	if tun.Stats().Connections != 1 {
+		t.Errorf("expected 1 connection, got %d", tun.Stats().Connections)
+	}
+}
+
+// TestReverseStop_ReleasesRemotePort verifies that stopping a reverse tunnel closes the listener on the SSH server.
+func TestReverseStop_ReleasesRemotePort(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewReverseTunnel(cfg, "127.0.0.1", freeTestPort(t), 8080)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	remoteAddr := tun.RemoteAddr()
+
+	if err := tun.Stop(); err != nil {
+		t.Fatalf("unexpected error on stop: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err := net.Dial("tcp", remoteAddr)
+	if err == nil {
+		t.Error("expected connection to fail after stop")
+	}
+}
+
+// freeTestPort returns a local TCP port that was free at the time of the call.
+func freeTestPort(t *testing.T) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// TestHealthCheck_Running verifies that a running tunnel with a live SSH connection passes the health check.
+func TestHealthCheck_Running(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := tun.HealthCheck(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestHealthCheck_NotRunning verifies that the health check of a tunnel that was never started returns ErrNotRunning.
+func TestHealthCheck_NotRunning(t *testing.T) {
+	cfg, _ := NewInsecureSSHConfig("user", "pass", "", "localhost", 22)
+	tun := NewTunnel(cfg, "remote-host", 1521, 0)
+
+	if err := tun.HealthCheck(context.Background()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+// TestHealthCheck_ConnectionLost verifies that the health check fails once the SSH server is gone.
+func TestHealthCheck_ConnectionLost(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	sshServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := tun.HealthCheck(ctx); err == nil {
+		t.Error("expected error after the ssh server is gone")
+	}
+}
+
+// TestIdleTimeout_StopsIdleTunnel verifies that a tunnel without activity stops itself and records ErrIdleTimeout.
+func TestIdleTimeout_StopsIdleTunnel(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.SetIdleTimeout(100 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	waitForStatus(t, tun, StatusStopped)
+
+	if !errors.Is(tun.LastError(), ErrIdleTimeout) {
+		t.Errorf("expected ErrIdleTimeout, got %v", tun.LastError())
+	}
+}
+
+// TestIdleTimeout_NotWhileActive verifies that the idle timeout does not fire while a connection is open.
+func TestIdleTimeout_NotWhileActive(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	release := make(chan struct{})
+	destServer := setupTestDestinationServerFunc(t, func(conn net.Conn) {
+		<-release
+		conn.Close()
+	})
+	defer destServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.SetIdleTimeout(100 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	conn, err := net.Dial("tcp", tun.LocalAddr())
+	if err != nil {
+		t.Fatalf("failed to connect to tunnel: %v", err)
+	}
+	defer conn.Close()
+
+	time.Sleep(300 * time.Millisecond)
+
+	if tun.Status() != StatusRunning {
+		t.Errorf("expected status %s while a connection is active, got %s", StatusRunning, tun.Status())
+	}
+
+	close(release)
+	waitForStatus(t, tun, StatusStopped)
+}
+
+// TestIdleTimeout_ManualStop verifies that a manual Stop before the timeout leaves no idle error behind.
+func TestIdleTimeout_ManualStop(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.SetIdleTimeout(100 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tun.Stop(); err != nil {
+		t.Fatalf("unexpected error on stop: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if tun.LastError() != nil {
+		t.Errorf("expected nil error, got %v", tun.LastError())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes from the tunnel goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestSetLogger_LogsLifecycleAndDialFailures verifies that lifecycle transitions and dial failures are logged.
+func TestSetLogger_LogsLifecycleAndDialFailures(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	var logs syncBuffer
+	tun := NewTunnel(cfg, "127.0.0.1", freeTestPort(t), 0)
+	tun.SetLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	readFromTunnel(t, tun.LocalAddr())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(logs.String(), "failed to dial target") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := tun.Stop(); err != nil {
+		t.Fatalf("unexpected error on stop: %v", err)
+	}
+
+	for _, msg := range []string{"tunnel started", "connection opened", "failed to dial target", "tunnel stopped"} {
+		if !strings.Contains(logs.String(), msg) {
+			t.Errorf("expected log to contain %q, got:\n%s", msg, logs.String())
+		}
+	}
+}
+
+// TestSetLogger_Nil verifies that a nil logger is replaced by one discarding everything.
+func TestSetLogger_Nil(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.SetLogger(nil)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tun.Stop(); err != nil {
+		t.Errorf("unexpected error on stop: %v", err)
+	}
+}
+
+// waitForStatus polls the tunnel until it reaches the expected status or fails the test after a timeout.
+func waitForStatus(t *testing.T, tun *Tunnel, want Status) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if tun.Status() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("expected status %s, got %s", want, tun.Status())
+}
+
+// setupTestSSHServer creates and starts an SSH server for testing purposes and returns the listener and SSH config.
 func setupTestSSHServer(t *testing.T) (net.Listener, *SSHConfig) {
 	t.Helper()
 
+	listener := startTestSSHServer(t, newTestPasswordServerConfig())
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	cfg, err := NewInsecureSSHConfig("testuser", "testpass", "", "127.0.0.1", port)
+	if err != nil {
+		listener.Close()
+		t.Fatalf("failed to create ssh config: %v", err)
+	}
+
+	return listener, cfg
+}
+
+// newTestPasswordServerConfig returns an SSH server config accepting the testuser/testpass credentials.
+func newTestPasswordServerConfig() *ssh.ServerConfig {
+	return &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "testuser" && string(pass) == "testpass" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials")
+		},
+	}
+}
+
+// testSSHServer is a test SSH server whose Close also drops the established client connections.
+type testSSHServer struct {
+	net.Listener
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+// Close stops accepting new connections and closes every connection accepted so far.
+func (s *testSSHServer) Close() error {
+	err := s.Listener.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+
+	return err
+}
+
+// startTestSSHServer starts an SSH server on a random local port using the provided server config and a fresh host key.
+func startTestSSHServer(t *testing.T, serverConfig *ssh.ServerConfig) *testSSHServer {
+	t.Helper()
+	return startTestSSHServerOn(t, "127.0.0.1:0", serverConfig)
+}
+
+// startTestSSHServerOn starts an SSH server listening on addr using the provided server config and a fresh host key.
+func startTestSSHServerOn(t *testing.T, addr string, serverConfig *ssh.ServerConfig) *testSSHServer {
+	t.Helper()
+
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		t.Fatalf("failed to generate private key: %v", err)
@@ -471,39 +1516,31 @@ func setupTestSSHServer(t *testing.T) (net.Listener, *SSHConfig) {
 		t.Fatalf("failed to create signer: %v", err)
 	}
 
-	serverConfig := &ssh.ServerConfig{
-		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
-			if c.User() == "testuser" && string(pass) == "testpass" {
-				return nil, nil
-			}
-			return nil, fmt.Errorf("invalid credentials")
-		},
-	}
 	serverConfig.AddHostKey(signer)
 
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		t.Fatalf("failed to create listener: %v", err)
 	}
 
+	server := &testSSHServer{Listener: listener}
+
 	go func() {
 		for {
 			conn, err := listener.Accept()
 			if err != nil {
 				return
 			}
+
+			server.mu.Lock()
+			server.conns = append(server.conns, conn)
+			server.mu.Unlock()
+
 			go handleTestSSHConnection(conn, serverConfig)
 		}
 	}()
 
-	port := listener.Addr().(*net.TCPAddr).Port
-	cfg, err := NewSSHConfig("testuser", "testpass", "", "127.0.0.1", "", port)
-	if err != nil {
-		listener.Close()
-		t.Fatalf("failed to create ssh config: %v", err)
-	}
-
-	return listener, cfg
+	return server
 }
 
 // handleTestSSHConnection manages an incoming SSH connection and handles direct-tcpip channel requests for forwarding.
@@ -516,16 +1553,10 @@ func handleTestSSHConnection(conn net.Conn, config *ssh.ServerConfig) {
 	}
 	defer sshConn.Close()
 
-	go ssh.DiscardRequests(reqs)
+	go handleTestGlobalRequests(sshConn, reqs)
 
 	for newChannel := range chans {
 		if newChannel.ChannelType() == "direct-tcpip" {
-			channel, requests, err := newChannel.Accept()
-			if err != nil {
-				continue
-			}
-			go ssh.DiscardRequests(requests)
-
 			var payload struct {
 				DestHost   string
 				DestPort   uint32
@@ -534,12 +1565,19 @@ func handleTestSSHConnection(conn net.Conn, config *ssh.ServerConfig) {
 			}
 			ssh.Unmarshal(newChannel.ExtraData(), &payload)
 
-			destAddr := fmt.Sprintf("%s:%d", payload.DestHost, payload.DestPort)
+			destAddr := net.JoinHostPort(payload.DestHost, strconv.Itoa(int(payload.DestPort)))
 			destConn, err := net.Dial("tcp", destAddr)
 			if err != nil {
-				channel.Close()
+				newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				destConn.Close()
 				continue
 			}
+			go ssh.DiscardRequests(requests)
 
 			go func() {
 				defer channel.Close()
@@ -555,6 +1593,106 @@ func handleTestSSHConnection(conn net.Conn, config *ssh.ServerConfig) {
 	}
 }
 
+// handleTestGlobalRequests serves tcpip-forward requests by listening locally and opening a forwarded-tcpip channel
+// back to the client for every accepted connection. Other global requests are rejected.
+func handleTestGlobalRequests(sshConn *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	var mu sync.Mutex
+	listeners := make(map[string]net.Listener)
+
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+
+	for req := range reqs {
+		var payload struct {
+			BindAddr string
+			BindPort uint32
+		}
+
+		switch req.Type {
+		case "tcpip-forward":
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+
+			listener, err := net.Listen("tcp", net.JoinHostPort(payload.BindAddr, strconv.Itoa(int(payload.BindPort))))
+			if err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+
+			port := uint32(listener.Addr().(*net.TCPAddr).Port)
+			mu.Lock()
+			listeners[net.JoinHostPort(payload.BindAddr, strconv.Itoa(int(port)))] = listener
+			mu.Unlock()
+
+			req.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+			go acceptTestForwardedConnections(sshConn, listener, payload.BindAddr, port)
+		case "cancel-tcpip-forward":
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+
+			key := net.JoinHostPort(payload.BindAddr, strconv.Itoa(int(payload.BindPort)))
+			mu.Lock()
+			listener, ok := listeners[key]
+			delete(listeners, key)
+			mu.Unlock()
+
+			if ok {
+				listener.Close()
+			}
+			req.Reply(ok, nil)
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// acceptTestForwardedConnections relays every connection accepted by listener to the client over a forwarded-tcpip channel.
+func acceptTestForwardedConnections(sshConn *ssh.ServerConn, listener net.Listener, bindAddr string, bindPort uint32) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		origin := conn.RemoteAddr().(*net.TCPAddr)
+		payload := ssh.Marshal(struct {
+			Addr       string
+			Port       uint32
+			OriginAddr string
+			OriginPort uint32
+		}{bindAddr, bindPort, origin.IP.String(), uint32(origin.Port)})
+
+		channel, requests, err := sshConn.OpenChannel("forwarded-tcpip", payload)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+
+		go func() {
+			defer channel.Close()
+			defer conn.Close()
+			io.Copy(channel, conn)
+		}()
+		go func() {
+			defer channel.Close()
+			defer conn.Close()
+			io.Copy(conn, channel)
+		}()
+	}
+}
+
 // setupTestDestinationServer creates a test TCP server that sends a fixed response to incoming connections.
 func setupTestDestinationServer(t *testing.T, response string) net.Listener {
 	t.Helper()
@@ -585,3 +1723,366 @@ func setupTestDestinationServerFunc(t *testing.T, handler func(net.Conn)) net.Li
 
 	return listener
 }
+
+func TestStats_UptimeAndIdleTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		stats      Stats
+		wantUptime time.Duration
+		wantIdle   time.Duration
+	}{
+		{
+			name: "not started",
+		},
+		{
+			name:       "no activity since start",
+			stats:      Stats{StartedAt: now.Add(-time.Minute)},
+			wantUptime: time.Minute,
+			wantIdle:   time.Minute,
+		},
+		{
+			name:       "recent activity",
+			stats:      Stats{StartedAt: now.Add(-time.Hour), LastActivity: now.Add(-5 * time.Second)},
+			wantUptime: time.Hour,
+			wantIdle:   5 * time.Second,
+		},
+		{
+			name:       "activity from a previous run",
+			stats:      Stats{StartedAt: now.Add(-10 * time.Second), LastActivity: now.Add(-time.Hour)},
+			wantUptime: 10 * time.Second,
+			wantIdle:   10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.uptime(now); got != tt.wantUptime {
+				t.Errorf("uptime = %v, want %v", got, tt.wantUptime)
+			}
+
+			if got := tt.stats.idleTime(now); got != tt.wantIdle {
+				t.Errorf("idleTime = %v, want %v", got, tt.wantIdle)
+			}
+		})
+	}
+}
+
+func TestStats_UptimeOfRunningTunnel(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+
+	if got := tun.Stats().Uptime(); got != 0 {
+		t.Errorf("Uptime() before Start = %v, want 0", got)
+	}
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+
+	stats := tun.Stats()
+	if stats.Uptime() < 20*time.Millisecond || stats.IdleTime() < 20*time.Millisecond {
+		t.Errorf("Uptime() = %v, IdleTime() = %v, want >= 20ms", stats.Uptime(), stats.IdleTime())
+	}
+}
+
+// fakeClock is a manually advanced clock for Tunnel.now and Tunnel.after.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+}
+
+// fakeTimer is a pending After call of a fakeClock.
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.waiters = slices.DeleteFunc(c.waiters, func(w fakeTimer) bool {
+		if c.now.Before(w.at) {
+			return false
+		}
+		w.ch <- c.now
+		return true
+	})
+}
+
+// TestClock_IdleTimeoutFollowsClock verifies that the idle timeout measures idleness with the tunnel clock.
+func TestClock_IdleTimeoutFollowsClock(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	clock := newFakeClock()
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.now = clock.Now
+	tun.SetIdleTimeout(20 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	time.Sleep(100 * time.Millisecond)
+	if tun.Status() != StatusRunning {
+		t.Fatalf("expected tunnel to keep running while the clock is frozen, got %s", tun.Status())
+	}
+
+	clock.Advance(time.Second)
+	waitForStatus(t, tun, StatusStopped)
+
+	if !errors.Is(tun.LastError(), ErrIdleTimeout) {
+		t.Errorf("expected ErrIdleTimeout, got %v", tun.LastError())
+	}
+}
+
+// TestClock_StatsAndEventsFollowClock verifies that statistics timestamps and connection durations use the tunnel clock.
+func TestClock_StatsAndEventsFollowClock(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "hello")
+	defer destServer.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+
+	tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+	tun.now = clock.Now
+
+	closed := make(chan ConnEvent, 1)
+	tun.OnConnection(func(ev ConnEvent) {
+		if ev.Type == ConnOpened {
+			clock.Advance(3 * time.Second)
+			return
+		}
+		closed <- ev
+	})
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tun.Close()
+
+	if got := tun.Stats().StartedAt; !got.Equal(start) {
+		t.Errorf("expected StartedAt %v, got %v", start, got)
+	}
+
+	readFromTunnel(t, tun.LocalAddr())
+
+	select {
+	case ev := <-closed:
+		if !ev.OpenedAt.Equal(start) || ev.Duration != 3*time.Second {
+			t.Errorf("expected event opened at %v lasting 3s, got %v lasting %v", start, ev.OpenedAt, ev.Duration)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for closed event")
+	}
+
+	if got := tun.Stats().LastActivity; !got.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("expected LastActivity %v, got %v", start.Add(3*time.Second), got)
+	}
+}
+
+// slowCloseConn is a net.Conn whose Close blocks until release is closed.
+type slowCloseConn struct {
+	net.Conn
+	release chan struct{}
+}
+
+func (c *slowCloseConn) Close() error {
+	<-c.release
+	return c.Conn.Close()
+}
+
+// TestSetStopTimeout_AbandonsSlowClient verifies that Stop gives up on an SSH client that does not close in time and
+// still leaves the tunnel stopped.
+func TestSetStopTimeout_AbandonsSlowClient(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.SetStopTimeout(50 * time.Millisecond)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", cfg.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	slowClient, err := handshakeSSH(context.Background(), &slowCloseConn{Conn: conn, release: release}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tun.mu.Lock()
+	origClient := tun.client
+	tun.client = slowClient
+	tun.mu.Unlock()
+
+	start := time.Now()
+	err = tun.Stop()
+	elapsed := time.Since(start)
+
+	_ = origClient.Close()
+
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Errorf("expected ErrStopTimeout, got %v", err)
+	}
+
+	if elapsed > time.Second {
+		t.Errorf("expected Stop to return after the stop timeout, took %v", elapsed)
+	}
+
+	if tun.Status() != StatusStopped {
+		t.Errorf("expected status %s, got %s", StatusStopped, tun.Status())
+	}
+}
+
+// TestSetStopTimeout_FastClose verifies that a stop timeout does not affect a client that closes promptly.
+func TestSetStopTimeout_FastClose(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	tun := NewTunnel(cfg, "127.0.0.1", 1521, 0)
+	tun.SetStopTimeout(time.Second)
+
+	if err := tun.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tun.Stop(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if tun.Status() != StatusStopped {
+		t.Errorf("expected status %s, got %s", StatusStopped, tun.Status())
+	}
+}
+
+func TestClientConfig_Algorithms(t *testing.T) {
+	cfg := &SSHConfig{
+		User:         "testuser",
+		Ciphers:      []string{"aes256-gcm@openssh.com"},
+		MACs:         []string{"hmac-sha2-256"},
+		KeyExchanges: []string{"ecdh-sha2-nistp384"},
+	}
+
+	got := clientConfig(cfg)
+
+	if !slices.Equal(got.Ciphers, cfg.Ciphers) {
+		t.Errorf("Ciphers = %v, want %v", got.Ciphers, cfg.Ciphers)
+	}
+	if !slices.Equal(got.MACs, cfg.MACs) {
+		t.Errorf("MACs = %v, want %v", got.MACs, cfg.MACs)
+	}
+	if !slices.Equal(got.KeyExchanges, cfg.KeyExchanges) {
+		t.Errorf("KeyExchanges = %v, want %v", got.KeyExchanges, cfg.KeyExchanges)
+	}
+}
+
+func TestClientConfig_DefaultAlgorithms(t *testing.T) {
+	got := clientConfig(&SSHConfig{User: "testuser"})
+
+	if !slices.Equal(got.KeyExchanges, defaultKeyExchanges) {
+		t.Errorf("KeyExchanges = %v, want %v", got.KeyExchanges, defaultKeyExchanges)
+	}
+	if got.Ciphers != nil || got.MACs != nil {
+		t.Errorf("expected Go default ciphers and MACs, got %v and %v", got.Ciphers, got.MACs)
+	}
+}
+
+func TestTunnel_StartWithCiphers(t *testing.T) {
+	serverConfig := newTestPasswordServerConfig()
+	serverConfig.Ciphers = []string{"aes128-ctr"}
+	sshServer := startTestSSHServer(t, serverConfig)
+	defer sshServer.Close()
+
+	destServer := setupTestDestinationServer(t, "ok")
+	defer destServer.Close()
+
+	tests := []struct {
+		name    string
+		ciphers []string
+		wantErr bool
+	}{
+		{name: "shared cipher", ciphers: []string{"aes256-ctr", "aes128-ctr"}},
+		{name: "no shared cipher", ciphers: []string{"aes256-gcm@openssh.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewInsecureSSHConfig("testuser", "testpass", "", "127.0.0.1", sshServer.Addr().(*net.TCPAddr).Port)
+			if err != nil {
+				t.Fatalf("failed to create ssh config: %v", err)
+			}
+			cfg.Ciphers = tt.ciphers
+
+			tun := NewTunnel(cfg, "127.0.0.1", destServer.Addr().(*net.TCPAddr).Port, 0)
+			err = tun.Start()
+			defer tun.Stop()
+
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("Start() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTunnel_StartDialTimeout(t *testing.T) {
+	sshServer, cfg := setupTestSSHServer(t)
+	defer sshServer.Close()
+
+	// A timeout that expires before the TCP connection can be made behaves like an unreachable bastion.
+	cfg.DialTimeout = time.Nanosecond
+
+	tun := NewTunnel(cfg, "127.0.0.1", 5432, 0)
+
+	start := time.Now()
+	err := tun.Start()
+	if !errors.Is(err, ErrDialTimeout) {
+		t.Fatalf("expected ErrDialTimeout, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Start took %s, expected it to fail fast", elapsed)
+	}
+
+	if tun.Status() != StatusError {
+		t.Errorf("expected status %s, got %s", StatusError, tun.Status())
+	}
+}